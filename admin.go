@@ -0,0 +1,268 @@
+package grip
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mongodb/grip/level"
+	"github.com/mongodb/grip/message"
+	"github.com/mongodb/grip/send"
+)
+
+// AdminOptions configures the handler that NewAdminHandler creates.
+type AdminOptions struct {
+	// Journaler is the logger that the handler inspects and
+	// controls, and defaults to the standard logger.
+	Journaler Journaler
+
+	// History is the number of recent messages that the handler's
+	// history Sender keeps, and defaults to 100.
+	History int
+}
+
+// AdminHandler is an http.Handler that allows operators to inspect
+// and change the levels of the Senders in a Journaler's sender tree
+// while the process is running, and to view recently logged messages.
+// Mount the handler on a debug or administrative port, with
+// http.StripPrefix if needed. The handler serves JSON:
+//
+//    GET /senders: the tree of senders, with their names, types,
+//        levels, and statistics. Each sender has a path (e.g.
+//        "0.1"), which is the position of the sender in the tree.
+//    GET /senders/<path>: a single sender.
+//    PUT /senders/<path>: changes the levels of a sender. The body
+//        is a document with "threshold" and/or "default" keys,
+//        whose values are the names of priorities. Changes to
+//        multi senders also apply to their members.
+//    GET /messages?limit=<n>: the most recent messages that the
+//        handler's history Sender received, newest last.
+//
+// The handler logs changes to the Journaler, at the notice level,
+// with the address of the client that made the change.
+type AdminHandler struct {
+	journaler Journaler
+	history   *send.RingSender
+}
+
+// NewAdminHandler constructs an AdminHandler. To record messages for
+// the /messages endpoint, add the handler's Sender to the Journaler's
+// sender tree, for example with send.NewConfiguredMultiSender.
+func NewAdminHandler(opts AdminOptions) *AdminHandler {
+	if opts.Journaler == nil {
+		opts.Journaler = std
+	}
+
+	if opts.History <= 0 {
+		opts.History = 100
+	}
+
+	history := send.MakeRingSender(opts.History)
+	history.SetName("admin-history")
+
+	return &AdminHandler{
+		journaler: opts.Journaler,
+		history:   history,
+	}
+}
+
+// Sender returns the Sender that records the messages that the
+// handler reports. The Sender never blocks, and keeps the most recent
+// messages that are loggable according to its level, which defaults
+// to recording all messages.
+func (h *AdminHandler) Sender() send.Sender { return h.history }
+
+// AdminSenderNode describes a Sender in the responses of the
+// AdminHandler.
+type AdminSenderNode struct {
+	Path      string            `json:"path"`
+	Name      string            `json:"name"`
+	Type      string            `json:"type"`
+	Threshold string            `json:"threshold"`
+	Default   string            `json:"default"`
+	Stats     *send.Stats       `json:"stats,omitempty"`
+	Children  []AdminSenderNode `json:"children,omitempty"`
+}
+
+// AdminMessage describes a message in the responses of the
+// AdminHandler.
+type AdminMessage struct {
+	Time     time.Time   `json:"time"`
+	Priority string      `json:"priority"`
+	Message  string      `json:"message"`
+	Raw      interface{} `json:"raw"`
+}
+
+// adminLevels is the body of requests to change the levels of a
+// Sender.
+type adminLevels struct {
+	Threshold string `json:"threshold"`
+	Default   string `json:"default"`
+}
+
+func (h *AdminHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(r.URL.Path, "/")
+
+	switch {
+	case path == "messages":
+		h.serveMessages(w, r)
+	case path == "senders":
+		if r.Method != http.MethodGet {
+			writeAdminError(w, http.StatusMethodNotAllowed, "%s is not supported", r.Method)
+			return
+		}
+		writeAdminJSON(w, http.StatusOK, describeSender("0", h.journaler.GetSender()))
+	case strings.HasPrefix(path, "senders/"):
+		h.serveSender(w, r, strings.TrimPrefix(path, "senders/"))
+	default:
+		writeAdminError(w, http.StatusNotFound, "'%s' is not found", r.URL.Path)
+	}
+}
+
+func (h *AdminHandler) serveMessages(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeAdminError(w, http.StatusMethodNotAllowed, "%s is not supported", r.Method)
+		return
+	}
+
+	limit := 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		num, err := strconv.Atoi(v)
+		if err != nil || num < 0 {
+			writeAdminError(w, http.StatusBadRequest, "limit '%s' is not valid", v)
+			return
+		}
+		limit = num
+	}
+
+	entries := h.history.Query(send.RingQuery{Limit: limit})
+	out := make([]AdminMessage, 0, len(entries))
+	for _, e := range entries {
+		out = append(out, AdminMessage{
+			Time:     e.Time,
+			Priority: e.Priority.String(),
+			Message:  e.Rendered,
+			Raw:      e.Raw,
+		})
+	}
+
+	writeAdminJSON(w, http.StatusOK, out)
+}
+
+func (h *AdminHandler) serveSender(w http.ResponseWriter, r *http.Request, path string) {
+	sender := findSender(h.journaler.GetSender(), path)
+	if sender == nil {
+		writeAdminError(w, http.StatusNotFound, "there is no sender at '%s'", path)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeAdminJSON(w, http.StatusOK, describeSender(path, sender))
+	case http.MethodPut:
+		req := adminLevels{}
+		dec := json.NewDecoder(r.Body)
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&req); err != nil {
+			writeAdminError(w, http.StatusBadRequest, "problem parsing request: %s", err.Error())
+			return
+		}
+
+		prev := sender.Level()
+		next := prev
+		for _, field := range []struct {
+			name  string
+			value string
+			out   *level.Priority
+		}{
+			{name: "threshold", value: req.Threshold, out: &next.Threshold},
+			{name: "default", value: req.Default, out: &next.Default},
+		} {
+			if field.value == "" {
+				continue
+			}
+
+			if *field.out = level.FromString(field.value); *field.out == level.Invalid {
+				writeAdminError(w, http.StatusBadRequest, "%s '%s' is not a valid priority", field.name, field.value)
+				return
+			}
+		}
+
+		if err := sender.SetLevel(next); err != nil {
+			writeAdminError(w, http.StatusBadRequest, "problem setting level: %s", err.Error())
+			return
+		}
+
+		h.journaler.Notice(message.NewFieldsMessage(level.Notice, "changed sender levels", message.Fields{
+			"path":               path,
+			"sender":             sender.Name(),
+			"previous_threshold": prev.Threshold.String(),
+			"previous_default":   prev.Default.String(),
+			"threshold":          next.Threshold.String(),
+			"default":            next.Default.String(),
+			"remote_addr":        r.RemoteAddr,
+		}))
+
+		writeAdminJSON(w, http.StatusOK, describeSender(path, sender))
+	default:
+		writeAdminError(w, http.StatusMethodNotAllowed, "%s is not supported", r.Method)
+	}
+}
+
+// findSender returns the Sender at the path in the tree of Senders,
+// or nil if there is no Sender at the path. The root of the tree has
+// the path "0", and the path of each member is the path of its parent
+// and its index, separated by a dot.
+func findSender(root send.Sender, path string) send.Sender {
+	parts := strings.Split(path, ".")
+	if parts[0] != "0" {
+		return nil
+	}
+
+	sender := root
+	for _, part := range parts[1:] {
+		idx, err := strconv.Atoi(part)
+		members := send.Members(sender)
+		if err != nil || idx < 0 || idx >= len(members) {
+			return nil
+		}
+		sender = members[idx]
+	}
+
+	return sender
+}
+
+func describeSender(path string, s send.Sender) AdminSenderNode {
+	l := s.Level()
+	node := AdminSenderNode{
+		Path:      path,
+		Name:      s.Name(),
+		Type:      fmt.Sprintf("%T", s),
+		Threshold: l.Threshold.String(),
+		Default:   l.Default.String(),
+	}
+
+	if reporter, ok := s.(send.StatsReporter); ok {
+		stats := reporter.Stats()
+		node.Stats = &stats
+	}
+
+	for idx, member := range send.Members(s) {
+		node.Children = append(node.Children, describeSender(fmt.Sprintf("%s.%d", path, idx), member))
+	}
+
+	return node
+}
+
+func writeAdminJSON(w http.ResponseWriter, code int, doc interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(doc)
+}
+
+func writeAdminError(w http.ResponseWriter, code int, msg string, args ...interface{}) {
+	writeAdminJSON(w, code, map[string]string{"error": fmt.Sprintf(msg, args...)})
+}
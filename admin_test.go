@@ -0,0 +1,152 @@
+package grip
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mongodb/grip/level"
+	"github.com/mongodb/grip/message"
+	"github.com/mongodb/grip/send"
+	"github.com/stretchr/testify/assert"
+)
+
+func adminRequest(h http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+	return rec
+}
+
+func TestAdminHandlerSenders(t *testing.T) {
+	assert := assert.New(t)
+
+	sink, err := send.NewInternalLogger("sink", send.LevelInfo{Default: level.Notice, Threshold: level.Info})
+	assert.NoError(err)
+
+	j := NewJournaler("admin")
+	h := NewAdminHandler(AdminOptions{Journaler: j})
+	assert.NoError(j.SetSender(send.NewConfiguredMultiSender(sink, h.Sender())))
+	j.SetThreshold(level.Info)
+
+	rec := adminRequest(h, http.MethodGet, "/senders", "")
+	assert.Equal(http.StatusOK, rec.Code)
+	assert.Equal("application/json", rec.Header().Get("Content-Type"))
+
+	tree := AdminSenderNode{}
+	assert.NoError(json.Unmarshal(rec.Body.Bytes(), &tree))
+	assert.Equal("0", tree.Path)
+	assert.Equal("admin", tree.Name)
+	assert.Equal("info", tree.Threshold)
+	assert.NotNil(tree.Stats)
+	if assert.Len(tree.Children, 2) {
+		assert.Equal("0.0", tree.Children[0].Path)
+		assert.Equal("*send.InternalSender", tree.Children[0].Type)
+	}
+
+	rec = adminRequest(h, http.MethodPut, "/senders/0.0", `{"threshold": "error"}`)
+	assert.Equal(http.StatusOK, rec.Code, rec.Body.String())
+	node := AdminSenderNode{}
+	assert.NoError(json.Unmarshal(rec.Body.Bytes(), &node))
+	assert.Equal("error", node.Threshold)
+	assert.Equal("notice", node.Default)
+	assert.Equal(level.Error, sink.Level().Threshold)
+	assert.Equal(level.Info, j.ThresholdLevel())
+
+	// the change is audited, and only recorded by the history
+	// sender, because the sink's threshold is now error.
+	rec = adminRequest(h, http.MethodGet, "/messages?limit=1", "")
+	assert.Equal(http.StatusOK, rec.Code)
+	msgs := []AdminMessage{}
+	assert.NoError(json.Unmarshal(rec.Body.Bytes(), &msgs))
+	if assert.Len(msgs, 1) {
+		assert.Equal("notice", msgs[0].Priority)
+		assert.Contains(msgs[0].Message, "changed sender levels")
+		raw := msgs[0].Raw.(map[string]interface{})
+		assert.Equal("0.0", raw["path"])
+		assert.Equal("info", raw["previous_threshold"])
+		assert.Equal("error", raw["threshold"])
+		assert.Equal("192.0.2.1:1234", raw["remote_addr"])
+	}
+
+	for path, code := range map[string]int{
+		"/senders/0.5":       http.StatusNotFound,
+		"/senders/1":         http.StatusNotFound,
+		"/senders/0.x":       http.StatusNotFound,
+		"/missing":           http.StatusNotFound,
+		"/messages?limit=-1": http.StatusBadRequest,
+	} {
+		assert.Equal(code, adminRequest(h, http.MethodGet, path, "").Code, path)
+	}
+
+	for body, msg := range map[string]string{
+		`{"threshold": "loud"}`: "threshold 'loud' is not a valid priority",
+		`{"colour": "red"}`:     "unknown field",
+		`not json`:              "problem parsing request",
+	} {
+		rec = adminRequest(h, http.MethodPut, "/senders/0", body)
+		assert.Equal(http.StatusBadRequest, rec.Code, body)
+		assert.Contains(rec.Body.String(), msg)
+	}
+
+	assert.Equal(http.StatusMethodNotAllowed, adminRequest(h, http.MethodDelete, "/senders/0", "").Code)
+	assert.Equal(http.StatusMethodNotAllowed, adminRequest(h, http.MethodPost, "/senders", "").Code)
+	assert.Equal(http.StatusMethodNotAllowed, adminRequest(h, http.MethodPost, "/messages", "").Code)
+}
+
+func TestAdminHandlerMessages(t *testing.T) {
+	assert := assert.New(t)
+
+	h := NewAdminHandler(AdminOptions{History: 3})
+	history := h.Sender()
+
+	history.Send(message.NewDefaultMessage(level.Info, "one"))
+	for _, msg := range []string{"two", "three", "four", "five"} {
+		history.Send(message.NewDefaultMessage(level.Info, msg))
+	}
+	history.Send(message.NewDefaultMessage(level.Info, ""))
+
+	msgs := []AdminMessage{}
+	rec := adminRequest(h, http.MethodGet, "/messages", "")
+	assert.Equal(http.StatusOK, rec.Code)
+	assert.NoError(json.Unmarshal(rec.Body.Bytes(), &msgs))
+	if assert.Len(msgs, 3) {
+		assert.Equal("three", msgs[0].Message)
+		assert.Equal("five", msgs[2].Message)
+	}
+
+	msgs = []AdminMessage{}
+	rec = adminRequest(h, http.MethodGet, "/messages?limit=2", "")
+	assert.NoError(json.Unmarshal(rec.Body.Bytes(), &msgs))
+	if assert.Len(msgs, 2) {
+		assert.Equal("four", msgs[0].Message)
+		assert.Equal("five", msgs[1].Message)
+	}
+
+	// the raw form is captured when the message is sent, rather
+	// than when the messages are requested
+	h = NewAdminHandler(AdminOptions{})
+	lazy := &lazyRawMessage{Composer: message.NewDefaultMessage(level.Info, "lazy")}
+	h.Sender().Send(lazy)
+
+	for i := 0; i < 2; i++ {
+		msgs = []AdminMessage{}
+		rec = adminRequest(h, http.MethodGet, "/messages", "")
+		assert.NoError(json.Unmarshal(rec.Body.Bytes(), &msgs))
+		if assert.Len(msgs, 1) {
+			assert.Equal(1.0, msgs[0].Raw.(map[string]interface{})["calls"])
+		}
+	}
+}
+
+// lazyRawMessage computes its Raw form each time it's called.
+type lazyRawMessage struct {
+	message.Composer
+	calls int
+}
+
+func (m *lazyRawMessage) Raw() interface{} {
+	m.calls++
+	return message.Fields{"calls": m.calls}
+}
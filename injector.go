@@ -1,6 +1,10 @@
 package grip
 
-import "github.com/mongodb/grip/send"
+import (
+	"time"
+
+	"github.com/mongodb/grip/send"
+)
 
 // SetSender swaps send.Sender() implementations in a logging
 // instance. Calls the Close() method on the existing instance before
@@ -15,3 +19,19 @@ func SetSender(s send.Sender) error {
 func GetSender() send.Sender {
 	return std.GetSender()
 }
+
+// Flush delivers the messages that the standard logger's senders are
+// holding, such as those in buffered senders, and returns once they
+// have been delivered or the timeout expires. If the timeout is 0,
+// Flush waits indefinitely. Call Flush before your program exits.
+func Flush(timeout time.Duration) error {
+	return std.Flush(timeout)
+}
+
+// SetCallSite enables or disables recording the call site of messages
+// that the standard logger logs, so that formatters and structured
+// senders can report the function, file, and line that logged each
+// message. Recording call sites has a cost for every logged message.
+func SetCallSite(enabled bool) {
+	std.SetCallSite(enabled)
+}
@@ -1,7 +1,11 @@
 package grip
 
 import (
+	"context"
+	"time"
+
 	"github.com/mongodb/grip/level"
+	"github.com/mongodb/grip/message"
 	"github.com/mongodb/grip/send"
 )
 
@@ -16,6 +20,15 @@ type Journaler interface {
 	GetSender() send.Sender
 	SetSender(send.Sender) error
 
+	// Deliver messages that the sender is holding, waiting at
+	// most for the specified duration.
+	Flush(time.Duration) error
+
+	// Enable or disable recording the call site of messages at
+	// log time.
+	SetCallSite(bool)
+	CallSite() bool
+
 	// Configure the default and threshold levels of the current
 	// sender.
 	SetThreshold(interface{})
@@ -32,6 +45,15 @@ type Journaler interface {
 	LogWhenf(bool, level.Priority, string, ...interface{})
 	LogWhenln(bool, level.Priority, ...interface{})
 
+	// Send a message and wait for it to be delivered, for
+	// messages that callers cannot afford to lose. These return
+	// an error if the sender could not deliver the message or the
+	// context is done first. Senders that do not implement
+	// send.ContextSender deliver the message using Send.
+	SendContext(context.Context, message.Composer) error
+	LogContext(context.Context, level.Priority, interface{}) error
+	LogfContext(context.Context, level.Priority, string, ...interface{}) error
+
 	// Log a message (the contents of the error,) only if the
 	// error is non-nil. These are redundant to the similar base
 	// methods. (e.g. Alert and CatchAlert have the same behavior.)
@@ -24,7 +24,12 @@ functionality. You must pass printf/println-style arguments to these methods.
 */
 package grip
 
-import "github.com/mongodb/grip/level"
+import (
+	"context"
+
+	"github.com/mongodb/grip/level"
+	"github.com/mongodb/grip/message"
+)
 
 func Log(l level.Priority, msg interface{}) {
 	std.Log(l, msg)
@@ -36,6 +41,18 @@ func Logln(l level.Priority, a ...interface{}) {
 	std.Logln(l, a...)
 }
 
+// SendContext sends a message with the standard logger and waits for
+// it to be delivered. See send.SendContext.
+func SendContext(ctx context.Context, m message.Composer) error {
+	return std.SendContext(ctx, m)
+}
+func LogContext(ctx context.Context, l level.Priority, msg interface{}) error {
+	return std.LogContext(ctx, l, msg)
+}
+func LogfContext(ctx context.Context, l level.Priority, msg string, a ...interface{}) error {
+	return std.LogfContext(ctx, l, msg, a...)
+}
+
 // Leveled Logging Methods
 // Emergency-level logging methods
 
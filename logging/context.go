@@ -0,0 +1,30 @@
+package logging
+
+import (
+	"context"
+
+	"github.com/mongodb/grip/level"
+	"github.com/mongodb/grip/message"
+	"github.com/mongodb/grip/send"
+)
+
+// SendContext sends a message and waits for the sender to deliver
+// it, returning an error if delivery failed or the context is done
+// first. If the sender does not implement send.ContextSender,
+// SendContext uses the sender's Send method and returns nil. See
+// send.SendContext.
+func (g *Grip) SendContext(ctx context.Context, m message.Composer) error {
+	g.recordCallSite(m)
+	return send.SendContext(ctx, g.Sender, m)
+}
+
+// LogContext converts the message to a message.Composer at the given
+// priority, as Log does, and sends it with SendContext.
+func (g *Grip) LogContext(ctx context.Context, l level.Priority, msg interface{}) error {
+	return g.SendContext(ctx, message.ConvertToComposer(l, msg))
+}
+
+// LogfContext is a printf-style variant of LogContext.
+func (g *Grip) LogfContext(ctx context.Context, l level.Priority, msg string, a ...interface{}) error {
+	return g.SendContext(ctx, message.NewFormattedMessage(l, msg, a...))
+}
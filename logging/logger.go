@@ -1,7 +1,10 @@
 package logging
 
 import (
+	"context"
 	"os"
+	"sync/atomic"
+	"time"
 
 	"github.com/mongodb/grip/level"
 	"github.com/mongodb/grip/message"
@@ -12,7 +15,14 @@ import (
 // interface is mirrored in the "grip" package's public interface, to
 // provide a single, global logging interface that requires minimal
 // configuration.
-type Grip struct{ send.Sender }
+type Grip struct {
+	send.Sender
+	callSite int32
+}
+
+// FatalFlushTimeout is the longest that the Fatal logging methods
+// wait for the sender to flush pending messages before exiting.
+var FatalFlushTimeout = 10 * time.Second
 
 // NewGrip takes the name for a logging instance and creates a new
 // Grip instance with configured with a local, standard output logging.
@@ -24,11 +34,55 @@ func NewGrip(name string) *Grip {
 			Default:   level.Notice,
 		})
 
-	return &Grip{sender}
+	return &Grip{Sender: sender}
+}
+
+// SetCallSite enables or disables recording the call site (the
+// function, file, and line that called the logging method) on
+// messages at log time, so that formatters and structured senders can
+// report it (see message.GetCallSite). Recording the call site walks
+// the stack for every message that the Journaler's sender would log,
+// so it is disabled by default.
+func (g *Grip) SetCallSite(enabled bool) {
+	var val int32
+	if enabled {
+		val = 1
+	}
+
+	atomic.StoreInt32(&g.callSite, val)
+}
+
+// CallSite returns true if the Journaler records the call site of
+// messages.
+func (g *Grip) CallSite() bool { return atomic.LoadInt32(&g.callSite) == 1 }
+
+// Send records the call site of the message, if enabled, and sends
+// it with the Journaler's sender.
+func (g *Grip) Send(m message.Composer) {
+	g.recordCallSite(m)
+	g.Sender.Send(m)
 }
 
 // Internal
 
+// recordCallSite records the first frame on the stack outside of grip
+// as the call site of messages that will be logged and do not already
+// have a call site.
+func (g *Grip) recordCallSite(m message.Composer) {
+	if !g.CallSite() || !g.Sender.Level().ShouldLog(m) {
+		return
+	}
+
+	c, ok := m.(message.CallSiteComposer)
+	if !ok {
+		return
+	}
+
+	if _, ok = c.GetCallSite(); !ok {
+		c.SetCallSite(message.CaptureCallSite())
+	}
+}
+
 // For sending logging messages, in most cases, use the
 // Journaler.sender.Send() method, but we have a couple of methods to
 // use for the Panic/Fatal helpers.
@@ -46,6 +100,22 @@ func (g *Grip) sendFatal(m message.Composer) {
 	// check but to add fatal methods we need to do this here.
 	if g.Level().ShouldLog(m) {
 		g.Send(m)
+		_ = g.Flush(FatalFlushTimeout)
 		os.Exit(1)
 	}
 }
+
+// Flush delivers the messages that the senders in the Journaler's
+// sender tree are holding (e.g. in buffered senders), and returns
+// once they have been delivered or the timeout expires. If the
+// timeout is 0, Flush waits indefinitely. See send.Flush.
+func (g *Grip) Flush(timeout time.Duration) error {
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	return send.Flush(ctx, g.Sender)
+}
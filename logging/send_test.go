@@ -1,15 +1,21 @@
 package logging
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/mongodb/grip/level"
 	"github.com/mongodb/grip/message"
 	"github.com/mongodb/grip/send"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -102,8 +108,27 @@ func (s *GripInternalSuite) TestConditionalSend() {
 	s.Equal(sink.GetMessage().Message, msg)
 }
 
+func (s *GripInternalSuite) TestContextSend() {
+	sink, err := send.NewInternalLogger("sink", s.grip.GetSender().Level())
+	s.NoError(err)
+	s.NoError(s.grip.SetSender(sink))
+
+	s.NoError(s.grip.LogContext(context.Background(), level.Info, "foo"))
+	s.Equal("foo", sink.GetMessage().Rendered)
+
+	s.NoError(s.grip.LogfContext(context.Background(), level.Info, "foo %d", 1))
+	s.Equal("foo 1", sink.GetMessage().Rendered)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for sink.Len() < 100 {
+		sink.Send(message.NewLineMessage(level.Info, "fill"))
+	}
+	s.Equal(context.Canceled, s.grip.SendContext(ctx, message.NewLineMessage(level.Info, "bar")))
+}
+
 func (s *GripInternalSuite) TestCatchMethods() {
-	sink, err := send.NewInternalLogger("sink", send.LevelInfo{level.Trace, level.Trace})
+	sink, err := send.NewInternalLogger("sink", send.LevelInfo{Default: level.Trace, Threshold: level.Trace})
 	s.NoError(err)
 	s.NoError(s.grip.SetSender(sink))
 
@@ -268,3 +293,122 @@ func TestSendFatalExits(t *testing.T) {
 		t.Errorf("sendFatal should have exited 0, instead: %+v", err)
 	}
 }
+
+func TestSendFatalFlushesBufferedMessages(t *testing.T) {
+	if os.Getenv("SHOULD_CRASH") == "1" {
+		grip := NewGrip("test")
+		sender, err := send.NewFileLogger("test", os.Getenv("LOG_FILE"), grip.GetSender().Level())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := grip.SetSender(send.NewBufferedSender(sender, time.Hour, 100)); err != nil {
+			t.Fatal(err)
+		}
+
+		grip.Notice("before the crash")
+		grip.sendFatal(message.NewLineMessage(level.Emergency, "exiting"))
+		return
+	}
+
+	dir, err := ioutil.TempDir("", "grip-fatal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	fn := filepath.Join(dir, "fatal.log")
+
+	cmd := exec.Command(os.Args[0], "-test.run=TestSendFatalFlushesBufferedMessages")
+	cmd.Env = append(os.Environ(), "SHOULD_CRASH=1", "LOG_FILE="+fn)
+	if err := cmd.Run(); err == nil {
+		t.Error("sendFatal should have exited with an error")
+	}
+
+	out, err := ioutil.ReadFile(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(out), "before the crash") || !strings.Contains(string(out), "exiting") {
+		t.Errorf("buffered messages were not flushed before exiting: %q", out)
+	}
+}
+
+func TestFlushDeliversBufferedMessages(t *testing.T) {
+	assert := assert.New(t)
+	grip := NewGrip("test")
+
+	sink, err := send.NewInternalLogger("sink", grip.GetSender().Level())
+	assert.NoError(err)
+	assert.NoError(grip.SetSender(send.NewBufferedSender(sink, time.Hour, 100)))
+
+	grip.Notice("hello")
+	assert.Equal(0, sink.Len())
+	assert.NoError(grip.Flush(time.Second))
+	assert.Equal(1, sink.Len())
+	assert.Equal("hello", sink.GetMessage().Rendered)
+}
+
+func TestCallSiteRecording(t *testing.T) {
+	assert := assert.New(t)
+
+	g := NewGrip("callsite")
+	sink, err := send.NewInternalLogger("sink", send.LevelInfo{Threshold: level.Info, Default: level.Info})
+	assert.NoError(err)
+	assert.NoError(g.SetSender(sink))
+	g.SetThreshold(level.Info)
+	for sink.HasMessage() {
+		sink.GetMessage()
+	}
+
+	// call sites are not recorded by default
+	assert.False(g.CallSite())
+	g.Info("disabled")
+	_, ok := message.GetCallSite(sink.GetMessage().Message)
+	assert.False(ok)
+
+	g.SetCallSite(true)
+	assert.True(g.CallSite())
+
+	for _, log := range []func(){
+		func() { g.Info("info") },
+		func() { g.Warningf("warning %d", 1) },
+		func() { g.Log(level.Error, message.Fields{"key": "value"}) },
+		func() { g.CatchError(errors.New("error")) },
+		func() { assert.NoError(g.LogContext(context.Background(), level.Info, "context")) },
+	} {
+		log()
+		msg := sink.GetMessage()
+		frame, ok := message.GetCallSite(msg.Message)
+		if assert.True(ok, msg.Rendered) {
+			assert.True(strings.HasPrefix(frame.Function, "github.com/mongodb/grip/logging.TestCallSiteRecording"), frame.Function)
+			assert.True(strings.HasSuffix(frame.File, "logging/send_test.go"), frame.File)
+			assert.NotZero(frame.Line)
+		}
+	}
+
+	// messages that will not be logged are not annotated
+	m := message.NewDefaultMessage(level.Debug, "filtered")
+	g.Send(m)
+	assert.False(sink.GetMessage().Logged)
+	_, ok = message.GetCallSite(m)
+	assert.False(ok)
+
+	// existing call sites and stack traces are preserved
+	stack := message.NewStack(1, "stack")
+	g.Error(stack)
+	frame, ok := message.GetCallSite(sink.GetMessage().Message)
+	assert.True(ok)
+	assert.Equal(stack.Raw().(message.StackTrace).Frames[0], frame)
+
+	group := message.MakeGroupComposer(message.NewDefaultMessage(level.Info, "one"), message.NewDefaultMessage(level.Info, "two"))
+	g.Info(group)
+	for _, member := range sink.GetMessage().Message.(*message.GroupComposer).Messages() {
+		_, ok = message.GetCallSite(member)
+		assert.True(ok)
+	}
+
+	g.SetCallSite(false)
+	g.Info("disabled again")
+	_, ok = message.GetCallSite(sink.GetMessage().Message)
+	assert.False(ok)
+}
@@ -18,6 +18,7 @@ type Base struct {
 	Time     time.Time      `bson:"time,omitempty" json:"time,omitempty" yaml:"time,omitempty"`
 	Process  string         `bson:"process,omitempty" json:"process,omitempty" yaml:"process,omitempty"`
 	Logger   string         `bson:"logger,omitempty" json:"logger,omitempty" yaml:"logger,omitempty"`
+	CallSite *StackFrame    `bson:"callsite,omitempty" json:"callsite,omitempty" yaml:"callsite,omitempty"`
 }
 
 // Collect records the time, process name, and hostname. Useful in the
@@ -55,3 +56,17 @@ func (b *Base) SetPriority(l level.Priority) error {
 
 	return nil
 }
+
+// SetCallSite records the location in the program that logged the
+// message.
+func (b *Base) SetCallSite(frame StackFrame) { b.CallSite = &frame }
+
+// GetCallSite returns the location in the program that logged the
+// message, if it was recorded.
+func (b *Base) GetCallSite() (StackFrame, bool) {
+	if b.CallSite == nil {
+		return StackFrame{}, false
+	}
+
+	return *b.CallSite, true
+}
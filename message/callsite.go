@@ -0,0 +1,71 @@
+package message
+
+import (
+	"runtime"
+	"strings"
+)
+
+// CallSiteComposer is implemented by Composers that can record the
+// location in the program that logged them, so that formatters and
+// structured senders can report it. All Composers that embed Base
+// implement CallSiteComposer.
+//
+// Journalers record call sites at log time when call site capture is
+// enabled (see the SetCallSite method of logging.Grip); use
+// CaptureCallSite to record call sites in your own infrastructure.
+type CallSiteComposer interface {
+	Composer
+	SetCallSite(StackFrame)
+	GetCallSite() (StackFrame, bool)
+}
+
+// GetCallSite returns the location in the program that logged the
+// message: the recorded call site, or, for stack messages, the first
+// frame of the stack trace. Returns false if the location is not known.
+func GetCallSite(m Composer) (StackFrame, bool) {
+	if c, ok := m.(CallSiteComposer); ok {
+		return c.GetCallSite()
+	}
+
+	return StackFrame{}, false
+}
+
+// CaptureCallSite returns the first frame on the current stack that is
+// outside of grip (and the runtime), which is the location in the
+// program that called a logging method. Frames in grip's own test
+// files are not skipped.
+func CaptureCallSite() StackFrame {
+	pcs := make([]uintptr, 64)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+
+	for {
+		frame, more := frames.Next()
+		if !isInternalFrame(frame) {
+			return StackFrame{
+				Function: frame.Function,
+				File:     frame.File,
+				Line:     frame.Line,
+			}
+		}
+
+		if !more {
+			return StackFrame{}
+		}
+	}
+}
+
+func isInternalFrame(frame runtime.Frame) bool {
+	if strings.HasSuffix(frame.File, "_test.go") {
+		return false
+	}
+
+	for _, prefix := range []string{"github.com/mongodb/grip", "runtime.", "text/template.", "reflect."} {
+		if strings.HasPrefix(frame.Function, prefix) {
+			return true
+		}
+	}
+
+	// functions in vendored copies of grip have the path of the
+	// vendor directory as a prefix.
+	return strings.Contains(frame.Function, "/vendor/github.com/mongodb/grip")
+}
@@ -208,3 +208,41 @@ func TestComposerConverter(t *testing.T) {
 	}
 
 }
+
+func TestCallSites(t *testing.T) {
+	assert := assert.New(t)
+
+	frame := CaptureCallSite()
+	assert.Equal("github.com/mongodb/grip/message.TestCallSites", frame.Function)
+	assert.True(strings.HasSuffix(frame.File, "message/composer_test.go"))
+
+	for _, m := range []Composer{
+		NewString("hello"),
+		NewFieldsMessage(level.Info, "hello", Fields{"a": 1}),
+		NewErrorMessage(level.Error, errors.New("hello")),
+		NewFormattedMessage(level.Info, "%s", "hello"),
+		NewLineMessage(level.Info, "hello"),
+		NewBytes([]byte("hello")),
+		MakeGroupComposer(NewString("one"), NewString("two")),
+	} {
+		_, ok := GetCallSite(m)
+		assert.False(ok)
+
+		c, ok := m.(CallSiteComposer)
+		if assert.True(ok, "%T", m) {
+			c.SetCallSite(frame)
+			recorded, ok := GetCallSite(m)
+			assert.True(ok)
+			assert.Equal(frame, recorded)
+		}
+	}
+
+	stack := NewStack(1, "hello")
+	recorded, ok := GetCallSite(stack)
+	assert.True(ok)
+	assert.Equal(stack.Raw().(StackTrace).Frames[0], recorded)
+
+	stack.(CallSiteComposer).SetCallSite(frame)
+	recorded, _ = GetCallSite(stack)
+	assert.Equal(frame, recorded)
+}
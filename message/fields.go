@@ -2,6 +2,7 @@ package message
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/mongodb/grip/level"
@@ -63,7 +64,16 @@ func (m *fieldMessage) String() string {
 			out = append(out, fmt.Sprintf(tmpl, "msg", m.message))
 		}
 
-		for k, v := range m.fields {
+		// sort the keys so that messages with the same fields
+		// always render the same string.
+		keys := make([]string, 0, len(m.fields))
+		for k := range m.fields {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			v := m.fields[k]
 			if k == "msg" && v == m.message {
 				continue
 			}
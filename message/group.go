@@ -109,3 +109,25 @@ func (g *GroupComposer) SetPriority(l level.Priority) error {
 func (g *GroupComposer) Messages() []Composer {
 	return g.messages
 }
+
+// SetCallSite records the call site on all constituent Composers that
+// support recording call sites.
+func (g *GroupComposer) SetCallSite(frame StackFrame) {
+	for _, m := range g.messages {
+		if c, ok := m.(CallSiteComposer); ok {
+			c.SetCallSite(frame)
+		}
+	}
+}
+
+// GetCallSite returns the first call site of the constituent
+// Composers.
+func (g *GroupComposer) GetCallSite() (StackFrame, bool) {
+	for _, m := range g.messages {
+		if frame, ok := GetCallSite(m); ok {
+			return frame, true
+		}
+	}
+
+	return StackFrame{}, false
+}
@@ -124,6 +124,20 @@ func (m *stackMessage) Raw() interface{} {
 	}
 }
 
+// GetCallSite returns the recorded call site, if any, and otherwise
+// the first frame of the stack trace.
+func (m *stackMessage) GetCallSite() (StackFrame, bool) {
+	if frame, ok := m.Base.GetCallSite(); ok {
+		return frame, true
+	}
+
+	if len(m.trace) == 0 {
+		return StackFrame{}, false
+	}
+
+	return m.trace[0], true
+}
+
 ////////////////////////////////////////////////////////////////////////
 //
 // Internal Operations for Collecting and processing data.
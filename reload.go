@@ -0,0 +1,195 @@
+package grip
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"sync"
+	"time"
+
+	"github.com/mongodb/grip/level"
+	"github.com/mongodb/grip/send"
+)
+
+// ConfigWatcherOptions configures the configuration file watcher that
+// WatchConfig starts.
+type ConfigWatcherOptions struct {
+	// Journaler is the logger whose sender the watcher replaces,
+	// and defaults to the standard logger.
+	Journaler Journaler
+
+	// Path is the path to the configuration file, which describes
+	// a tree of senders as a send.SenderConfig.
+	Path string
+
+	// Interval is how often the watcher checks the file for
+	// changes, and defaults to 5 seconds.
+	Interval time.Duration
+
+	// Unmarshal parses the file, as in send.ParseSenderConfig,
+	// and defaults to reading JSON.
+	Unmarshal func([]byte, interface{}) error
+}
+
+// WatchConfig builds the tree of senders that a configuration file
+// describes (see send.BuildSender), installs it in the Journaler, and
+// then checks the file for changes at the specified interval. When
+// the file changes, the watcher builds the new tree of senders and
+// swaps it into the Journaler, without losing messages that other
+// goroutines are logging: the previous senders are flushed and closed
+// once messages that are being sent to them have been delivered.
+//
+// If the changed configuration is not valid, or the senders cannot be
+// built, the watcher logs the error and continues to use the previous
+// senders. Returns an error if the initial configuration is not
+// valid. The Journaler's threshold becomes the lowest threshold of
+// the senders in the tree, so that each sender filters messages with
+// its own threshold, and its default level becomes the default level
+// of the configuration's root. The Journaler's name is the default
+// name of the senders. The returned function stops watching the file.
+func WatchConfig(opts ConfigWatcherOptions) (func(), error) {
+	if opts.Journaler == nil {
+		opts.Journaler = std
+	}
+
+	if opts.Path == "" {
+		return nil, errors.New("must specify a configuration file to watch")
+	}
+
+	if opts.Interval <= 0 {
+		opts.Interval = 5 * time.Second
+	}
+
+	w := &configWatcher{opts: opts}
+
+	data, err := ioutil.ReadFile(opts.Path)
+	if err != nil {
+		return nil, fmt.Errorf("problem reading logging configuration: %s", err.Error())
+	}
+
+	conf, sender, err := w.build(data)
+	if err != nil {
+		return nil, err
+	}
+
+	w.sender = send.NewSwapSender(sender)
+	if err = opts.Journaler.SetSender(w.sender); err != nil {
+		_ = sender.Close()
+		return nil, err
+	}
+
+	if err = w.sender.SetLevel(treeLevelInfo(conf, sender)); err != nil {
+		return nil, err
+	}
+	w.last = data
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(opts.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				w.check()
+			}
+		}
+	}()
+
+	once := &sync.Once{}
+	return func() { once.Do(func() { close(done) }) }, nil
+}
+
+type configWatcher struct {
+	opts    ConfigWatcherOptions
+	sender  *send.SwapSender
+	last    []byte
+	readErr string
+}
+
+func (w *configWatcher) build(data []byte) (*send.SenderConfig, send.Sender, error) {
+	conf, err := send.ParseSenderConfig(data, w.opts.Unmarshal)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if conf.Name == "" {
+		conf.Name = w.opts.Journaler.Name()
+	}
+
+	sender, err := send.BuildSender(conf)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return conf, sender, nil
+}
+
+// check reloads the configuration if the contents of the file have
+// changed since the last check.
+func (w *configWatcher) check() {
+	j := w.opts.Journaler
+
+	data, err := ioutil.ReadFile(w.opts.Path)
+	if err != nil {
+		// the file may be missing while it's being replaced, so
+		// only report each error once.
+		if err.Error() != w.readErr {
+			w.readErr = err.Error()
+			j.Errorf("problem reading logging configuration from '%s', keeping the previous configuration: %s",
+				w.opts.Path, err)
+		}
+		return
+	}
+	w.readErr = ""
+
+	if bytes.Equal(data, w.last) {
+		return
+	}
+
+	// only report each version of the file once.
+	w.last = data
+
+	conf, sender, err := w.build(data)
+	if err != nil {
+		j.Errorf("problem loading logging configuration from '%s', keeping the previous configuration: %s",
+			w.opts.Path, err)
+		return
+	}
+
+	if err = w.sender.SetLevel(treeLevelInfo(conf, sender)); err != nil {
+		_ = sender.Close()
+		j.Errorf("problem loading logging configuration from '%s', keeping the previous configuration: %s",
+			w.opts.Path, err)
+		return
+	}
+
+	j.CatchError(w.sender.Swap(sender))
+	j.Noticef("reloaded logging configuration from '%s'", w.opts.Path)
+}
+
+// treeLevelInfo returns the default level of the configuration's root
+// and the lowest threshold of the senders in the tree.
+func treeLevelInfo(conf *send.SenderConfig, sender send.Sender) send.LevelInfo {
+	l := conf.LevelInfo()
+
+	var lowest level.Priority
+	senders := []send.Sender{sender}
+	for len(senders) > 0 {
+		s := senders[0]
+		senders = append(senders[1:], send.Members(s)...)
+
+		if t := s.Level().Threshold; level.IsValidPriority(t) && (lowest == level.Invalid || t < lowest) {
+			lowest = t
+		}
+	}
+
+	if lowest != level.Invalid {
+		l.Threshold = lowest
+	}
+
+	return l
+}
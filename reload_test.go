@@ -0,0 +1,142 @@
+package grip
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mongodb/grip/level"
+	"github.com/mongodb/grip/send"
+	"github.com/stretchr/testify/assert"
+)
+
+// eventually returns true once the predicate does, or false if the
+// predicate is still false after 5 seconds.
+func eventually(pred func() bool) bool {
+	timeout := time.After(5 * time.Second)
+	for !pred() {
+		select {
+		case <-timeout:
+			return false
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	return true
+}
+
+func fileContains(fn, text string) bool {
+	data, _ := ioutil.ReadFile(fn)
+	return strings.Contains(string(data), text)
+}
+
+func TestWatchConfigReloadsSenders(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "grip-reload")
+	assert.NoError(err)
+	defer os.RemoveAll(dir)
+
+	confPath := filepath.Join(dir, "logging.json")
+	first := filepath.Join(dir, "first.log")
+	second := filepath.Join(dir, "second.log")
+
+	writeConfig := func(threshold, path string) {
+		assert.NoError(ioutil.WriteFile(confPath, []byte(fmt.Sprintf(`{
+			"type": "buffered",
+			"threshold": "%s",
+			"senders": [{"type": "file", "formatter": {"type": "plain"}, "options": {"path": "%s"}}]
+		}`, threshold, filepath.ToSlash(path))), 0600))
+	}
+
+	j := NewJournaler("reload")
+	_, err = WatchConfig(ConfigWatcherOptions{Journaler: j})
+	assert.Error(err)
+	_, err = WatchConfig(ConfigWatcherOptions{Journaler: j, Path: confPath})
+	assert.Error(err)
+
+	writeConfig("info", first)
+	stop, err := WatchConfig(ConfigWatcherOptions{Journaler: j, Path: confPath, Interval: 10 * time.Millisecond})
+	assert.NoError(err)
+	defer stop()
+
+	assert.Equal(level.Info, j.ThresholdLevel())
+	assert.Equal("reload", j.GetSender().(*send.SwapSender).Current().Name())
+	j.Debug("hidden")
+	j.Info("first message")
+
+	// the new configuration lowers the threshold and changes the
+	// file; messages buffered for the first file are delivered.
+	writeConfig("debug", second)
+	assert.True(eventually(func() bool {
+		_ = j.Flush(time.Second)
+		return fileContains(second, "reloaded logging configuration")
+	}))
+	assert.Equal(level.Debug, j.ThresholdLevel())
+	assert.True(fileContains(first, "first message"))
+	assert.False(fileContains(first, "hidden"))
+
+	j.Debug("second message")
+	assert.NoError(j.Flush(time.Second))
+	assert.True(fileContains(second, "second message"))
+
+	// invalid configurations are reported, and do not replace the
+	// senders.
+	assert.NoError(ioutil.WriteFile(confPath, []byte(`{"type": "buffered", "threshold": "loud"}`), 0600))
+	assert.True(eventually(func() bool {
+		_ = j.Flush(time.Second)
+		return fileContains(second, "keeping the previous configuration")
+	}))
+	assert.True(fileContains(second, "$.threshold: 'loud' is not a valid priority"))
+	assert.Equal(level.Debug, j.ThresholdLevel())
+}
+
+func TestWatchConfigKeepsChildThresholds(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "grip-reload")
+	assert.NoError(err)
+	defer os.RemoveAll(dir)
+
+	confPath := filepath.Join(dir, "logging.json")
+	debug := filepath.Join(dir, "debug.log")
+	info := filepath.Join(dir, "info.log")
+
+	writeConfig := func(threshold string) {
+		assert.NoError(ioutil.WriteFile(confPath, []byte(fmt.Sprintf(`{
+			"type": "multi",
+			"senders": [
+				{"type": "file", "threshold": "%s", "formatter": {"type": "plain"}, "options": {"path": "%s"}},
+				{"type": "file", "formatter": {"type": "plain"}, "options": {"path": "%s"}}
+			]
+		}`, threshold, filepath.ToSlash(debug), filepath.ToSlash(info))), 0600))
+	}
+
+	writeConfig("info")
+	j := NewJournaler("reload")
+	stop, err := WatchConfig(ConfigWatcherOptions{Journaler: j, Path: confPath, Interval: 10 * time.Millisecond})
+	assert.NoError(err)
+	defer stop()
+
+	assert.Equal(level.Info, j.ThresholdLevel())
+	j.Debug("hidden")
+
+	// the root's threshold is info, but one of its senders logs
+	// debug messages.
+	writeConfig("debug")
+	assert.True(eventually(func() bool {
+		_ = j.Flush(time.Second)
+		return fileContains(info, "reloaded logging configuration")
+	}))
+	assert.Equal(level.Debug, j.ThresholdLevel())
+
+	j.Debug("debugging")
+	assert.NoError(j.Flush(time.Second))
+	assert.True(fileContains(debug, "debugging"))
+	assert.False(fileContains(info, "debugging"))
+	assert.False(fileContains(debug, "hidden"))
+}
@@ -0,0 +1,275 @@
+package send
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mongodb/grip/level"
+	"github.com/mongodb/grip/message"
+)
+
+const (
+	defaultBacktraceSize    = 100
+	defaultBacktraceMaxKeys = 1000
+)
+
+// BacktraceOptions configures the backtrace Sender.
+type BacktraceOptions struct {
+	// Trigger is the lowest priority that flushes the buffered
+	// messages, and defaults to level.Error.
+	Trigger level.Priority
+
+	// Capture is the lowest priority of messages to buffer, and
+	// defaults to level.Trace. Messages below the Sender's
+	// threshold and at or above this priority are buffered.
+	Capture level.Priority
+
+	// Size is the maximum number of messages in each buffer,
+	// and defaults to 100. Duration, if set, is the maximum age
+	// of buffered messages, in addition to the limit on their
+	// number.
+	Size     int
+	Duration time.Duration
+
+	// Key is the name of a field that holds a request or
+	// correlation id. When set, messages whose Raw form is a
+	// message.Fields value with this field are buffered
+	// separately for each value, and a triggering message only
+	// flushes the buffer for its own id. MaxKeys limits the
+	// number of ids with buffers (the least recently used
+	// buffers are discarded first), and defaults to 1000.
+	Key     string
+	MaxKeys int
+}
+
+// Validate checks the options and sets the default values.
+func (o *BacktraceOptions) Validate() error {
+	if o == nil {
+		return errors.New("backtrace options cannot be nil")
+	}
+
+	errs := []string{}
+
+	if o.Trigger == level.Invalid {
+		o.Trigger = level.Error
+	} else if !level.IsValidPriority(o.Trigger) {
+		errs = append(errs, fmt.Sprintf("trigger priority %d is not valid", o.Trigger))
+	}
+
+	if o.Capture == level.Invalid {
+		o.Capture = level.Trace
+	} else if !level.IsValidPriority(o.Capture) {
+		errs = append(errs, fmt.Sprintf("capture priority %d is not valid", o.Capture))
+	}
+
+	if o.Size < 0 || o.Duration < 0 || o.MaxKeys < 0 {
+		errs = append(errs, "size, duration, and maximum keys must not be negative")
+	}
+
+	if o.Size == 0 {
+		o.Size = defaultBacktraceSize
+	}
+
+	if o.MaxKeys == 0 {
+		o.MaxKeys = defaultBacktraceMaxKeys
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+type backtraceEntry struct {
+	added time.Time
+	msg   message.Composer
+}
+
+// backtraceRing is a fixed-size circular buffer of messages.
+type backtraceRing struct {
+	key     string
+	entries []backtraceEntry
+	start   int
+	count   int
+}
+
+func newBacktraceRing(key string, size int) *backtraceRing {
+	return &backtraceRing{key: key, entries: make([]backtraceEntry, size)}
+}
+
+func (r *backtraceRing) push(e backtraceEntry) {
+	idx := (r.start + r.count) % len(r.entries)
+	r.entries[idx] = e
+
+	if r.count < len(r.entries) {
+		r.count++
+	} else {
+		r.start = (r.start + 1) % len(r.entries)
+	}
+}
+
+// drain returns the buffered messages added after the cutoff, oldest
+// first, and empties the buffer.
+func (r *backtraceRing) drain(cutoff time.Time) []message.Composer {
+	out := make([]message.Composer, 0, r.count)
+	for i := 0; i < r.count; i++ {
+		idx := (r.start + i) % len(r.entries)
+		if r.entries[idx].added.After(cutoff) {
+			out = append(out, r.entries[idx].msg)
+		}
+		r.entries[idx] = backtraceEntry{}
+	}
+
+	r.start = 0
+	r.count = 0
+
+	return out
+}
+
+type backtraceSender struct {
+	senderWrapper
+	opts    BacktraceOptions
+	global  *backtraceRing
+	keyed   map[string]*list.Element
+	recency *list.List
+	mutex   sync.Mutex
+}
+
+// NewBacktraceSender wraps an existing Sender, and buffers messages
+// that are below the wrapped Sender's threshold rather than dropping
+// them. When a message at or above the trigger priority arrives, the
+// Sender sends the buffered messages, followed by the triggering
+// message, to the wrapped Sender, as a single message.GroupComposer,
+// so that you can run at a high threshold but still see the debugging
+// context that preceded an error. The buffers only retain the most
+// recent messages, as configured by the BacktraceOptions.
+//
+// The members of a message.GroupComposer are handled individually.
+func NewBacktraceSender(sender Sender, opts BacktraceOptions) (Sender, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &backtraceSender{
+		senderWrapper: newSenderWrapper(sender),
+		opts:          opts,
+		global:        newBacktraceRing("", opts.Size),
+		keyed:         map[string]*list.Element{},
+		recency:       list.New(),
+	}, nil
+}
+
+func (s *backtraceSender) Send(m message.Composer) {
+	if group, ok := m.(*message.GroupComposer); ok {
+		for _, member := range group.Messages() {
+			s.send(member)
+		}
+		return
+	}
+
+	s.send(m)
+}
+
+func (s *backtraceSender) send(m message.Composer) {
+	if !m.Loggable() {
+		s.stats.recordFiltered()
+		return
+	}
+
+	p := m.Priority()
+	l := s.Level()
+	start := time.Now()
+
+	switch {
+	case p >= s.opts.Trigger:
+		context := s.drain(s.key(m))
+		if len(context) == 0 {
+			s.Sender.Send(m)
+		} else {
+			s.Sender.Send(message.NewGroupComposer(append(context, m)))
+		}
+		s.stats.recordSent(p, time.Since(start))
+	case l.ShouldLog(m):
+		s.Sender.Send(m)
+		s.stats.recordSent(p, time.Since(start))
+	case p >= s.opts.Capture:
+		s.buffer(s.key(m), m)
+	default:
+		s.stats.recordFiltered()
+	}
+}
+
+func (s *backtraceSender) key(m message.Composer) string {
+	if s.opts.Key == "" {
+		return ""
+	}
+
+	fields, ok := m.Raw().(message.Fields)
+	if !ok {
+		return ""
+	}
+
+	v, ok := fields[s.opts.Key]
+	if !ok {
+		return ""
+	}
+
+	return fmt.Sprint(v)
+}
+
+func (s *backtraceSender) buffer(key string, m message.Composer) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	entry := backtraceEntry{added: time.Now(), msg: m}
+
+	if key == "" {
+		s.global.push(entry)
+		return
+	}
+
+	if elem, ok := s.keyed[key]; ok {
+		s.recency.MoveToFront(elem)
+		elem.Value.(*backtraceRing).push(entry)
+		return
+	}
+
+	if s.recency.Len() >= s.opts.MaxKeys {
+		oldest := s.recency.Back()
+		s.recency.Remove(oldest)
+		delete(s.keyed, oldest.Value.(*backtraceRing).key)
+	}
+
+	ring := newBacktraceRing(key, s.opts.Size)
+	ring.push(entry)
+	s.keyed[key] = s.recency.PushFront(ring)
+}
+
+func (s *backtraceSender) drain(key string) []message.Composer {
+	var cutoff time.Time
+	if s.opts.Duration > 0 {
+		cutoff = time.Now().Add(-s.opts.Duration)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if key == "" {
+		return s.global.drain(cutoff)
+	}
+
+	elem, ok := s.keyed[key]
+	if !ok {
+		return nil
+	}
+
+	s.recency.Remove(elem)
+	delete(s.keyed, key)
+
+	return elem.Value.(*backtraceRing).drain(cutoff)
+}
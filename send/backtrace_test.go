@@ -0,0 +1,111 @@
+package send
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mongodb/grip/level"
+	"github.com/mongodb/grip/message"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBacktraceOptionsValidation(t *testing.T) {
+	assert := assert.New(t)
+
+	var opts *BacktraceOptions
+	assert.Error(opts.Validate())
+
+	opts = &BacktraceOptions{}
+	assert.NoError(opts.Validate())
+	assert.Equal(level.Error, opts.Trigger)
+	assert.Equal(level.Trace, opts.Capture)
+	assert.Equal(defaultBacktraceSize, opts.Size)
+
+	// the size limit applies when only the duration is set
+	opts = &BacktraceOptions{Duration: time.Minute}
+	assert.NoError(opts.Validate())
+	assert.Equal(defaultBacktraceSize, opts.Size)
+
+	assert.Error((&BacktraceOptions{Trigger: 1000}).Validate())
+	assert.Error((&BacktraceOptions{Size: -1}).Validate())
+}
+
+func TestBacktraceSenderFlushesContext(t *testing.T) {
+	assert := assert.New(t)
+
+	sink, err := NewInternalLogger("backtrace", LevelInfo{level.Info, level.Info})
+	require.NoError(t, err)
+	s, err := NewBacktraceSender(sink, BacktraceOptions{Size: 3, Capture: level.Debug})
+	require.NoError(t, err)
+
+	s.Send(message.NewDefaultMessage(level.Trace, "not captured"))
+	for i := 0; i < 5; i++ {
+		s.Send(message.NewDefaultMessage(level.Debug, fmt.Sprintf("debug %d", i)))
+	}
+	assert.Equal(0, sink.Len())
+
+	s.Send(message.NewDefaultMessage(level.Info, "info"))
+	assert.Equal(1, sink.Len())
+	assert.Equal("info", sink.GetMessage().Rendered)
+
+	s.Send(message.NewDefaultMessage(level.Error, "failed"))
+	require.Equal(t, 1, sink.Len())
+	out := sink.GetMessage()
+	assert.True(out.Logged)
+	assert.Equal(level.Error, out.Priority)
+	assert.Equal("debug 2\ndebug 3\ndebug 4\nfailed", out.Rendered)
+
+	// the buffer is empty after flushing
+	s.Send(message.NewDefaultMessage(level.Critical, "failed again"))
+	assert.Equal("failed again", sink.GetMessage().Rendered)
+}
+
+func TestBacktraceSenderDuration(t *testing.T) {
+	assert := assert.New(t)
+
+	sink, err := NewInternalLogger("backtrace", LevelInfo{level.Info, level.Info})
+	require.NoError(t, err)
+	s, err := NewBacktraceSender(sink, BacktraceOptions{Duration: 20 * time.Millisecond})
+	require.NoError(t, err)
+
+	s.Send(message.NewDefaultMessage(level.Debug, "old"))
+	time.Sleep(50 * time.Millisecond)
+	s.Send(message.NewDefaultMessage(level.Debug, "new"))
+	s.Send(message.NewDefaultMessage(level.Error, "failed"))
+
+	assert.Equal("new\nfailed", sink.GetMessage().Rendered)
+}
+
+func TestBacktraceSenderScopesByKey(t *testing.T) {
+	assert := assert.New(t)
+
+	sink, err := NewInternalLogger("backtrace", LevelInfo{level.Info, level.Info})
+	require.NoError(t, err)
+	s, err := NewBacktraceSender(sink, BacktraceOptions{Key: "request", MaxKeys: 2})
+	require.NoError(t, err)
+
+	s.Send(message.MakeGroupComposer(
+		message.NewFields(level.Debug, message.Fields{"request": "a", "step": 1}),
+		message.NewFields(level.Debug, message.Fields{"request": "b", "step": 1}),
+		message.NewFields(level.Debug, message.Fields{"request": "a", "step": 2}),
+		message.NewDefaultMessage(level.Debug, "unscoped"),
+	))
+	assert.Equal(0, sink.Len())
+
+	s.Send(message.NewFields(level.Error, message.Fields{"request": "a", "failed": true}))
+	out := sink.GetMessage()
+	group := out.Message.(*message.GroupComposer)
+	assert.Len(group.Messages(), 3)
+	assert.Equal("[request='a' step='1']", group.Messages()[0].String())
+
+	// adding a third key discards the least recently used buffer
+	s.Send(message.NewFields(level.Debug, message.Fields{"request": "c"}))
+	s.Send(message.NewFields(level.Debug, message.Fields{"request": "d"}))
+	s.Send(message.NewFields(level.Error, message.Fields{"request": "b"}))
+	assert.Equal("[request='b']", sink.GetMessage().Rendered)
+
+	s.Send(message.NewDefaultMessage(level.Error, "failed"))
+	assert.Equal("unscoped\nfailed", sink.GetMessage().Rendered)
+}
@@ -15,12 +15,14 @@ type Base struct {
 	// data exposed via the interface and tools to track them
 	name  string
 	level LevelInfo
+	stats *senderStats
 	mutex sync.RWMutex
 
 	// function literals which allow customizable functionality.
 	// they are set either in the constructor (e.g. MakeBase) of
 	// via the SetErrorHandler/SetFormatter injector.
 	errHandler ErrorHandler
+	handler    ErrorHandler
 	reset      func()
 	closer     func() error
 	formatter  MessageFormatter
@@ -29,12 +31,16 @@ type Base struct {
 // NewBase constructs a basic Base structure with no op functions for
 // reset, close, and error handling.
 func NewBase(n string) *Base {
-	return &Base{
-		name:       n,
-		reset:      func() {},
-		closer:     func() error { return nil },
-		errHandler: func(error, message.Composer) {},
+	b := &Base{
+		name:   n,
+		reset:  func() {},
+		closer: func() error { return nil },
+		stats:  newSenderStats(),
 	}
+	b.handler = func(error, message.Composer) {}
+	b.errHandler = b.countErrors(b.handler)
+
+	return b
 }
 
 // MakeBase constructs a Base structure that allows callers to specify
@@ -88,11 +94,30 @@ func (b *Base) SetErrorHandler(eh ErrorHandler) error {
 
 	b.mutex.Lock()
 	defer b.mutex.Unlock()
-	b.errHandler = eh
+	b.handler = eh
+	b.errHandler = b.countErrors(eh)
 
 	return nil
 }
 
+// getErrorHandler returns the error handler that was set, without the
+// wrapper that counts errors, so that wrappers can chain to it.
+func (b *Base) getErrorHandler() ErrorHandler {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	return b.handler
+}
+
+// countErrors wraps an error handler so that the errors it handles
+// are included in the Sender's Stats.
+func (b *Base) countErrors(eh ErrorHandler) ErrorHandler {
+	return func(err error, m message.Composer) {
+		b.stats.recordError(err)
+		eh(err, m)
+	}
+}
+
 // ErrorHandler calls the error handler, and is a wrapper around the
 // embedded ErrorHandler function. It is not part of the Sender interface.
 func (b *Base) ErrorHandler(err error, m message.Composer) {
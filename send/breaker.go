@@ -0,0 +1,521 @@
+package send
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mongodb/grip/level"
+	"github.com/mongodb/grip/message"
+)
+
+const (
+	defaultBreakerThreshold = 5
+	defaultBreakerCoolDown  = 30 * time.Second
+)
+
+// CircuitBreakerOptions configures the circuit breaker Sender.
+type CircuitBreakerOptions struct {
+	// Fallback receives messages while the circuit is open,
+	// messages that the wrapped Sender failed to deliver, and
+	// messages about changes in the state of the circuit. If
+	// Fallback is nil, these messages are dropped.
+	Fallback Sender
+
+	// Threshold is the number of consecutive delivery failures
+	// that open the circuit, and defaults to 5.
+	Threshold int
+
+	// CoolDown is how long the circuit stays open before
+	// allowing a trial message through, and defaults to 30
+	// seconds.
+	CoolDown time.Duration
+
+	// Timeout is the longest the breaker waits for the wrapped
+	// Sender's Send method to return before considering the
+	// delivery failed. If Timeout is 0, the breaker waits
+	// indefinitely.
+	Timeout time.Duration
+}
+
+// Validate checks the options and sets the default values.
+func (o *CircuitBreakerOptions) Validate() error {
+	if o == nil {
+		return errors.New("circuit breaker options cannot be nil")
+	}
+
+	errs := []string{}
+	if o.Threshold < 0 || o.CoolDown < 0 || o.Timeout < 0 {
+		errs = append(errs, "threshold, cool down, and timeout must not be negative")
+	}
+
+	if o.Threshold == 0 {
+		o.Threshold = defaultBreakerThreshold
+	}
+
+	if o.CoolDown == 0 {
+		o.CoolDown = defaultBreakerCoolDown
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+// CircuitState describes the state of a circuit breaker Sender.
+type CircuitState int
+
+// Circuit breaker states: when closed, messages go to the wrapped
+// Sender; when open, they go to the fallback; and when half-open, a
+// single trial message goes to the wrapped Sender to decide whether
+// to close the circuit or to open it again.
+const (
+	CircuitClosed CircuitState = iota
+	CircuitOpen
+	CircuitHalfOpen
+)
+
+// String returns a human-readable name for the state.
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "invalid"
+	}
+}
+
+type circuitBreakerSender struct {
+	senderWrapper
+	opts       CircuitBreakerOptions
+	state      CircuitState
+	failures   int
+	errors     int
+	openedAt   time.Time
+	trial      bool
+	errHandler ErrorHandler
+	inflight   map[message.Composer]*breakerDelivery
+	notes      []message.Composer
+	mutex      sync.Mutex
+}
+
+// breakerDelivery tracks a message that the breaker is delivering with
+// a timeout, so that errors that the wrapped Sender reports after the
+// timeout, when the message has gone to the fallback, are ignored.
+type breakerDelivery struct {
+	timedOut bool
+	failed   bool
+}
+
+// NewCircuitBreakerSender wraps a Sender, typically one that delivers
+// messages over the network, with a circuit breaker, so that an
+// unreachable service does not slow down every logging call.
+//
+// The breaker considers a delivery failed when the wrapped Sender
+// reports an error to its error handler, or when Send does not return
+// within the timeout; the constructor replaces the wrapped Sender's
+// error handler to observe these errors, and the breaker calls the
+// previous error handler, unless SetErrorHandler replaces it. Messages
+// that time out go to the fallback, and errors that the wrapped Sender
+// reports for them later are ignored, but the wrapped Sender may still
+// deliver them.
+//
+// After enough consecutive failures, the breaker opens and sends
+// messages to the fallback Sender. After the cool down, the breaker
+// lets one message through to the wrapped Sender, and closes if it is
+// delivered or opens again if it fails. The breaker sends a message to
+// the fallback Sender whenever its state changes.
+//
+// Closing the breaker closes the wrapped Sender, but not the fallback.
+func NewCircuitBreakerSender(sender Sender, opts CircuitBreakerOptions) (Sender, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	s := &circuitBreakerSender{
+		senderWrapper: newSenderWrapper(sender),
+		opts:          opts,
+		inflight:      map[message.Composer]*breakerDelivery{},
+	}
+
+	if prev, ok := sender.(interface{ getErrorHandler() ErrorHandler }); ok {
+		s.errHandler = prev.getErrorHandler()
+	}
+
+	if err := sender.SetErrorHandler(s.handleError); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// GetCircuitState is a helper function that returns the state of a
+// circuit breaker Sender, and returns an error if the Sender is not a
+// circuit breaker.
+func GetCircuitState(breaker Sender) (CircuitState, error) {
+	s, ok := breaker.(*circuitBreakerSender)
+	if !ok {
+		return CircuitClosed, fmt.Errorf("%s is not a circuit breaker sender", breaker.Name())
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.state, nil
+}
+
+// SetErrorHandler sets a handler that the breaker calls, in addition
+// to sending the failed message to the fallback, when the wrapped
+// Sender fails to deliver a message.
+func (s *circuitBreakerSender) SetErrorHandler(eh ErrorHandler) error {
+	if eh == nil {
+		return errors.New("error handler must be non-nil")
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.errHandler = eh
+
+	return nil
+}
+
+func (s *circuitBreakerSender) Send(m message.Composer) {
+	if !s.Level().ShouldLog(m) {
+		s.stats.recordFiltered()
+		return
+	}
+
+	allowed, errCount := s.allow()
+	if !allowed {
+		s.fallback(m)
+		return
+	}
+
+	start := time.Now()
+	if !s.deliver(m) {
+		s.stats.recordError(fmt.Errorf("timed out after %s", s.opts.Timeout))
+		s.fallback(m)
+		s.recordFailure()
+		return
+	}
+
+	// the error handler has already counted messages that the
+	// wrapped sender failed to deliver.
+	if s.recordSuccess(errCount) {
+		s.stats.recordSent(m.Priority(), time.Since(start))
+	}
+}
+
+// SendContext sends the message to the wrapped Sender, if the circuit
+// allows it, and returns the wrapped Sender's error, if any, after
+// sending the message to the fallback. The breaker's Timeout applies
+// in addition to the context's deadline, and errors caused by the
+// caller canceling the context do not count as delivery failures.
+// While the circuit is open, SendContext sends the message to the
+// fallback and returns the fallback's error, or an error if there is
+// no fallback.
+func (s *circuitBreakerSender) SendContext(ctx context.Context, m message.Composer) error {
+	if !s.Level().ShouldLog(m) {
+		s.stats.recordFiltered()
+		return nil
+	}
+
+	allowed, errCount := s.allow()
+	if !allowed {
+		if s.opts.Fallback == nil {
+			s.stats.recordDropped()
+			return fmt.Errorf("circuit breaker for '%s' is open", s.Name())
+		}
+
+		return SendContext(ctx, s.opts.Fallback, m)
+	}
+
+	sendCtx := ctx
+	if s.opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		sendCtx, cancel = context.WithTimeout(ctx, s.opts.Timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	if err := SendContext(sendCtx, s.Sender, m); err != nil {
+		s.stats.recordError(err)
+		s.fallback(m)
+
+		if ctx.Err() == nil {
+			s.recordFailure()
+		} else {
+			s.abandonTrial()
+		}
+
+		return err
+	}
+
+	s.stats.recordSent(m.Priority(), time.Since(start))
+	s.recordSuccess(errCount)
+
+	return nil
+}
+
+// abandonTrial allows another message to try the wrapped Sender when
+// the trial message's delivery was canceled by the caller.
+func (s *circuitBreakerSender) abandonTrial() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.trial = false
+}
+
+func (s *circuitBreakerSender) members() []Sender {
+	if s.opts.Fallback == nil {
+		return []Sender{s.Sender}
+	}
+
+	return []Sender{s.Sender, s.opts.Fallback}
+}
+
+func (s *circuitBreakerSender) deliver(m message.Composer) bool {
+	if s.opts.Timeout <= 0 {
+		s.Sender.Send(m)
+		return true
+	}
+
+	delivery := s.track(m)
+
+	done := make(chan struct{})
+	go func() {
+		s.Sender.Send(m)
+		s.untrack(m, delivery)
+		close(done)
+	}()
+
+	timer := time.NewTimer(s.opts.Timeout)
+	defer timer.Stop()
+
+	select {
+	case <-done:
+		return true
+	case <-timer.C:
+	}
+
+	if delivery == nil {
+		return false
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// if the wrapped sender reported an error before the timeout,
+	// the error handler has already handled the message.
+	delivery.timedOut = !delivery.failed
+
+	return delivery.failed
+}
+
+// track records that the message is being delivered with a timeout.
+// Returns nil for messages that cannot be tracked, because their type
+// is not comparable, or because the same message is already in flight.
+func (s *circuitBreakerSender) track(m message.Composer) *breakerDelivery {
+	if !reflect.TypeOf(m).Comparable() {
+		return nil
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.inflight[m]; ok {
+		return nil
+	}
+
+	delivery := &breakerDelivery{}
+	s.inflight[m] = delivery
+
+	return delivery
+}
+
+func (s *circuitBreakerSender) untrack(m message.Composer, delivery *breakerDelivery) {
+	if delivery == nil {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.inflight, m)
+}
+
+// allow reports whether a message should go to the wrapped Sender,
+// and returns the current error count so that the caller can detect
+// errors reported during delivery.
+func (s *circuitBreakerSender) allow() (bool, int) {
+	s.mutex.Lock()
+	defer s.unlock()
+
+	switch s.state {
+	case CircuitOpen:
+		if time.Since(s.openedAt) < s.opts.CoolDown {
+			return false, s.errors
+		}
+
+		s.setState(CircuitHalfOpen)
+		s.trial = true
+		return true, s.errors
+	case CircuitHalfOpen:
+		if s.trial {
+			return false, s.errors
+		}
+
+		s.trial = true
+		return true, s.errors
+	default:
+		return true, s.errors
+	}
+}
+
+// recordSuccess closes the circuit after a delivery, and reports
+// whether the wrapped Sender delivered the message without reporting
+// an error.
+func (s *circuitBreakerSender) recordSuccess(errCount int) bool {
+	s.mutex.Lock()
+	defer s.unlock()
+
+	// the wrapped sender reported an error while delivering
+	// this message, which the error handler has recorded.
+	if s.errors != errCount {
+		return false
+	}
+
+	s.failures = 0
+	if s.state == CircuitHalfOpen {
+		s.trial = false
+		s.setState(CircuitClosed)
+	}
+
+	return true
+}
+
+func (s *circuitBreakerSender) recordFailure() {
+	s.mutex.Lock()
+	defer s.unlock()
+
+	s.errors++
+	s.failures++
+
+	switch s.state {
+	case CircuitHalfOpen:
+		s.trial = false
+		s.openedAt = time.Now()
+		s.setState(CircuitOpen)
+	case CircuitClosed:
+		if s.failures >= s.opts.Threshold {
+			s.openedAt = time.Now()
+			s.setState(CircuitOpen)
+		}
+	}
+}
+
+func (s *circuitBreakerSender) handleError(err error, m message.Composer) {
+	if err == nil {
+		return
+	}
+
+	if s.timedOut(m) {
+		return
+	}
+
+	s.stats.recordError(err)
+	s.fallback(m)
+	s.recordFailure()
+
+	s.mutex.Lock()
+	eh := s.errHandler
+	s.mutex.Unlock()
+
+	if eh != nil {
+		eh(err, m)
+	}
+}
+
+// timedOut reports whether the message timed out, and has already gone
+// to the fallback, and otherwise marks tracked messages as failed.
+func (s *circuitBreakerSender) timedOut(m message.Composer) bool {
+	if !reflect.TypeOf(m).Comparable() {
+		return false
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delivery, ok := s.inflight[m]
+	if !ok {
+		return false
+	}
+
+	if delivery.timedOut {
+		return true
+	}
+	delivery.failed = true
+
+	return false
+}
+
+// unlock releases the lock, and then sends the notifications about
+// changes in the state of the circuit to the fallback, so that the
+// fallback is never called with the lock held.
+func (s *circuitBreakerSender) unlock() {
+	notes := s.notes
+	s.notes = nil
+	s.mutex.Unlock()
+
+	for _, note := range notes {
+		s.opts.Fallback.Send(note)
+	}
+}
+
+// setState changes the state and queues a notification for the
+// fallback. Callers must hold the lock, and release it with unlock.
+func (s *circuitBreakerSender) setState(state CircuitState) {
+	if s.state == state {
+		return
+	}
+	s.state = state
+
+	p := level.Notice
+	if state == CircuitOpen {
+		p = level.Warning
+	}
+
+	if s.opts.Fallback == nil {
+		return
+	}
+
+	s.notes = append(s.notes, message.NewFieldsMessage(p,
+		fmt.Sprintf("circuit breaker for '%s' is %s", s.Name(), state),
+		message.Fields{
+			"sender":   s.Name(),
+			"state":    state.String(),
+			"failures": s.failures,
+		}))
+}
+
+// fallback sends the message to the fallback Sender, counting messages
+// that the breaker drops because there is no fallback.
+func (s *circuitBreakerSender) fallback(m message.Composer) {
+	if s.opts.Fallback == nil {
+		s.stats.recordDropped()
+		return
+	}
+
+	s.opts.Fallback.Send(m)
+}
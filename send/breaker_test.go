@@ -0,0 +1,196 @@
+package send
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mongodb/grip/level"
+	"github.com/mongodb/grip/message"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakySender reports an error for every message while failing is
+// set, and blocks for the delay before returning from Send.
+type flakySender struct {
+	failing bool
+	delay   time.Duration
+	sent    int
+	mutex   sync.Mutex
+	*Base
+}
+
+func newFlakySender() *flakySender {
+	s := &flakySender{Base: NewBase("flaky")}
+	s.level = LevelInfo{level.Info, level.Info}
+	return s
+}
+
+func (s *flakySender) setFailing(f bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.failing = f
+}
+
+func (s *flakySender) Send(m message.Composer) {
+	if !s.level.ShouldLog(m) {
+		return
+	}
+
+	time.Sleep(s.delay)
+
+	s.mutex.Lock()
+	failing := s.failing
+	if !failing {
+		s.sent++
+	}
+	s.mutex.Unlock()
+
+	if failing {
+		s.ErrorHandler(errors.New("unreachable"), m)
+	}
+}
+
+func TestCircuitBreakerOptionsValidation(t *testing.T) {
+	assert := assert.New(t)
+
+	var opts *CircuitBreakerOptions
+	assert.Error(opts.Validate())
+	assert.Error((&CircuitBreakerOptions{Threshold: -1}).Validate())
+
+	opts = &CircuitBreakerOptions{}
+	assert.NoError(opts.Validate())
+	assert.Equal(defaultBreakerThreshold, opts.Threshold)
+	assert.Equal(defaultBreakerCoolDown, opts.CoolDown)
+}
+
+func TestCircuitBreakerSenderStates(t *testing.T) {
+	assert := assert.New(t)
+
+	fallback, err := NewInternalLogger("fallback", LevelInfo{level.Trace, level.Trace})
+	require.NoError(t, err)
+	flaky := newFlakySender()
+	s, err := NewCircuitBreakerSender(flaky, CircuitBreakerOptions{
+		Fallback:  fallback,
+		Threshold: 2,
+		CoolDown:  20 * time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	handled := 0
+	assert.NoError(s.SetErrorHandler(func(error, message.Composer) { handled++ }))
+	assert.Error(s.SetErrorHandler(nil))
+
+	s.Send(message.NewDefaultMessage(level.Info, "delivered"))
+	assert.Equal(1, flaky.sent)
+	assert.Equal(0, fallback.Len())
+
+	flaky.setFailing(true)
+	s.Send(message.NewDefaultMessage(level.Info, "one"))
+	state, err := GetCircuitState(s)
+	assert.NoError(err)
+	assert.Equal(CircuitClosed, state)
+	s.Send(message.NewDefaultMessage(level.Info, "two"))
+	state, _ = GetCircuitState(s)
+	assert.Equal(CircuitOpen, state)
+	assert.Equal(2, handled)
+
+	// failed messages and the state change go to the fallback
+	require.Equal(t, 3, fallback.Len())
+	assert.Equal("one", fallback.GetMessage().Rendered)
+	assert.Equal("two", fallback.GetMessage().Rendered)
+	change := fallback.GetMessage()
+	assert.Equal(level.Warning, change.Priority)
+	assert.Equal("open", change.Message.Raw().(message.Fields)["state"])
+
+	// while open, messages go directly to the fallback
+	flaky.setFailing(false)
+	s.Send(message.NewDefaultMessage(level.Info, "rerouted"))
+	assert.Equal(1, flaky.sent)
+	assert.Equal("rerouted", fallback.GetMessage().Rendered)
+
+	// after the cool down, a successful trial closes the circuit
+	time.Sleep(40 * time.Millisecond)
+	s.Send(message.NewDefaultMessage(level.Info, "trial"))
+	assert.Equal(2, flaky.sent)
+	state, _ = GetCircuitState(s)
+	assert.Equal(CircuitClosed, state)
+	assert.Equal("half-open", fallback.GetMessage().Message.Raw().(message.Fields)["state"])
+	assert.Equal("closed", fallback.GetMessage().Message.Raw().(message.Fields)["state"])
+	assert.Equal(0, fallback.Len())
+
+	_, err = GetCircuitState(fallback)
+	assert.Error(err)
+}
+
+func TestCircuitBreakerSenderTimeout(t *testing.T) {
+	assert := assert.New(t)
+
+	fallback, err := NewInternalLogger("fallback", LevelInfo{level.Trace, level.Trace})
+	require.NoError(t, err)
+	flaky := newFlakySender()
+	flaky.delay = 100 * time.Millisecond
+	s, err := NewCircuitBreakerSender(flaky, CircuitBreakerOptions{
+		Fallback:  fallback,
+		Threshold: 1,
+		CoolDown:  time.Hour,
+		Timeout:   10 * time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	start := time.Now()
+	s.Send(message.NewDefaultMessage(level.Info, "slow"))
+	s.Send(message.NewDefaultMessage(level.Info, "rerouted"))
+	assert.True(time.Since(start) < 80*time.Millisecond)
+
+	state, _ := GetCircuitState(s)
+	assert.Equal(CircuitOpen, state)
+	assert.Equal(3, fallback.Len())
+}
+
+func TestCircuitBreakerSenderKeepsErrorHandler(t *testing.T) {
+	assert := assert.New(t)
+
+	flaky := newFlakySender()
+	flaky.setFailing(true)
+	handled := 0
+	assert.NoError(flaky.SetErrorHandler(func(error, message.Composer) { handled++ }))
+
+	s, err := NewCircuitBreakerSender(flaky, CircuitBreakerOptions{})
+	require.NoError(t, err)
+
+	s.Send(message.NewDefaultMessage(level.Info, "failed"))
+	assert.Equal(1, handled)
+}
+
+func TestCircuitBreakerSenderIgnoresLateErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	fallback, err := NewInternalLogger("fallback", LevelInfo{level.Trace, level.Trace})
+	require.NoError(t, err)
+	flaky := newFlakySender()
+	flaky.setFailing(true)
+	flaky.delay = 30 * time.Millisecond
+	handled := 0
+	assert.NoError(flaky.SetErrorHandler(func(error, message.Composer) { handled++ }))
+
+	s, err := NewCircuitBreakerSender(flaky, CircuitBreakerOptions{
+		Fallback:  fallback,
+		Threshold: 2,
+		Timeout:   5 * time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	s.Send(message.NewDefaultMessage(level.Info, "slow"))
+	time.Sleep(60 * time.Millisecond)
+
+	// the late error neither resends the message nor counts again
+	require.Equal(t, 1, fallback.Len())
+	assert.Equal("slow", fallback.GetMessage().Rendered)
+	assert.Equal(0, handled)
+	state, _ := GetCircuitState(s)
+	assert.Equal(CircuitClosed, state)
+	assert.EqualValues(1, s.(StatsReporter).Stats().Errors)
+}
@@ -1,6 +1,8 @@
 package send
 
 import (
+	"context"
+	"sync"
 	"time"
 
 	"github.com/mongodb/grip/message"
@@ -9,12 +11,15 @@ import (
 const minBufferLength = 5 * time.Second
 
 type bufferedSender struct {
-	Sender
+	senderWrapper
 
 	duration time.Duration
 	number   int
 	pipe     chan message.Composer
 	signal   chan struct{}
+	flushes  chan chan struct{}
+	finished chan struct{}
+	closer   sync.Once
 }
 
 // NewBufferedSender provides a Sender implementation that wraps an
@@ -25,6 +30,9 @@ type bufferedSender struct {
 // is sent individually. Furthermore, no more than 2 batches of events
 // can be sent at once.
 //
+// Use Flush to send buffered messages before the duration has passed;
+// closing the Sender also sends the buffered messages.
+//
 // If the duration is 0, the constructor sets a duration of 24 hours,
 // and if the duration is not 5 seconds, the constructor sets a 5 second
 // duration. If the number threshold is 0, then the constructor sets a
@@ -41,11 +49,13 @@ func NewBufferedSender(sender Sender, duration time.Duration, number int) Sender
 	}
 
 	s := &bufferedSender{
-		Sender:   sender,
-		duration: duration,
-		number:   number,
-		pipe:     make(chan message.Composer, number),
-		signal:   make(chan struct{}),
+		senderWrapper: newSenderWrapper(sender),
+		duration:      duration,
+		number:        number,
+		pipe:          make(chan message.Composer, number),
+		signal:        make(chan struct{}),
+		flushes:       make(chan chan struct{}),
+		finished:      make(chan struct{}),
 	}
 
 	go s.backgroundWorker()
@@ -62,8 +72,8 @@ daemon:
 	for {
 		select {
 		case msg := <-s.pipe:
+			buffer = append(buffer, msg)
 			if len(buffer) < s.number {
-				buffer = append(buffer, msg)
 				continue daemon
 			}
 
@@ -74,8 +84,18 @@ daemon:
 			go s.backgroundSender(buffer, complete)
 			buffer = []message.Composer{}
 			timer.Reset(s.duration)
+		case done := <-s.flushes:
+			go s.backgroundSender(s.drain(buffer), complete)
+			buffer = []message.Composer{}
+			timer.Reset(s.duration)
+
+			<-complete
+			close(done)
+			continue daemon
 		case <-s.signal:
+			buffer = s.drain(buffer)
 			close(s.pipe)
+			go s.backgroundSender(buffer, complete)
 			close(s.signal)
 			break daemon
 		}
@@ -84,16 +104,68 @@ daemon:
 	}
 
 	<-complete
+	close(s.finished)
 	_ = s.Sender.Close()
 }
 
+// drain adds the messages waiting in the pipe to the buffer.
+func (s *bufferedSender) drain(buffer []message.Composer) []message.Composer {
+	for {
+		select {
+		case msg := <-s.pipe:
+			buffer = append(buffer, msg)
+		default:
+			return buffer
+		}
+	}
+}
+
+// Flush sends the buffered messages to the wrapped Sender, and then
+// flushes the wrapped Sender.
+func (s *bufferedSender) Flush(ctx context.Context) error {
+	if err := s.sendBuffer(ctx); err != nil {
+		return err
+	}
+
+	return Flush(ctx, s.Sender)
+}
+
+// sendBuffer sends the buffered messages to the wrapped Sender, and
+// returns once they have been sent, or the context is done.
+func (s *bufferedSender) sendBuffer(ctx context.Context) error {
+	done := make(chan struct{})
+
+	select {
+	case s.flushes <- done:
+	case <-s.finished:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 func (s *bufferedSender) backgroundSender(msgs []message.Composer, complete chan struct{}) {
+	start := time.Now()
+
 	if len(msgs) == 1 {
 		s.Sender.Send(msgs[0])
 	} else if len(msgs) > 1 {
 		s.Sender.Send(message.NewGroupComposer(msgs))
 	}
 
+	latency := time.Since(start)
+	for _, m := range msgs {
+		s.stats.recordSent(m.Priority(), latency)
+	}
+	s.stats.addQueued(-int64(len(msgs)))
+
 	complete <- struct{}{}
 }
 
@@ -101,20 +173,40 @@ func (s *bufferedSender) Send(msg message.Composer) {
 	switch msg := msg.(type) {
 	case *message.GroupComposer:
 		for _, m := range msg.Messages() {
+			s.stats.addQueued(1)
 			s.pipe <- m
 		}
 	default:
+		s.stats.addQueued(1)
 		s.pipe <- msg
 	}
 }
 
-func (s *bufferedSender) Close() error {
-	// do a non-blocking send on the signal thread to close the
-	// background worker
-	select {
-	case s.signal <- struct{}{}:
-	default:
+// SendContext sends the buffered messages, and then sends the message
+// to the wrapped Sender, so that callers that need to know whether a
+// message was delivered are not delayed until the buffer fills up, and
+// the message does not overtake the messages in the buffer.
+func (s *bufferedSender) SendContext(ctx context.Context, m message.Composer) error {
+	start := time.Now()
+	if err := s.sendBuffer(ctx); err != nil {
+		return err
 	}
 
+	if err := SendContext(ctx, s.Sender, m); err != nil {
+		return err
+	}
+	s.stats.recordSent(m.Priority(), time.Since(start))
+
+	return nil
+}
+
+// Close sends the buffered messages, and closes the wrapped Sender
+// once they have been sent.
+func (s *bufferedSender) Close() error {
+	s.closer.Do(func() {
+		s.signal <- struct{}{}
+		<-s.finished
+	})
+
 	return nil
 }
@@ -2,8 +2,10 @@ package send
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
@@ -17,11 +19,13 @@ import (
 )
 
 type buildlogger struct {
-	conf   *BuildloggerConfig
-	name   string
-	testID string
-	cache  chan []interface{}
-	client *http.Client
+	conf     *BuildloggerConfig
+	name     string
+	testID   string
+	cache    chan []interface{}
+	flushes  chan chan error
+	finished chan struct{}
+	client   *http.Client
 	*Base
 }
 
@@ -239,29 +243,63 @@ func MakeBuildlogger(name string, conf *BuildloggerConfig) (Sender, error) {
 	}
 
 	stop := make(chan struct{})
-	finished := make(chan struct{})
+	b.flushes = make(chan chan error)
+	b.finished = make(chan struct{})
 	b.closer = func() error {
 		signal := struct{}{}
 		for {
 			select {
 			case stop <- signal:
 				continue
-			case <-finished:
+			case <-b.finished:
 				return nil
 			}
 		}
 	}
-	go b.backgroundSender(stop, finished)
+	go b.backgroundSender(stop, b.finished)
 
 	return b, nil
 }
 
 func (b *buildlogger) Send(m message.Composer) {
-	if b.level.ShouldLog(m) {
+	if b.shouldLog(m) {
+		defer b.recordSend(m, time.Now())
+
+		b.stats.addQueued(1)
 		b.cache <- []interface{}{float64(time.Now().Unix()), m.String()}
 	}
 }
 
+// SendContext posts the buffered messages, and then posts the message
+// to the buildlogger service, rather than adding it to the buffer of
+// messages that Send uses, and returns once the service has responded.
+// Errors posting the buffered messages go to the error handler, as
+// they do for Send.
+func (b *buildlogger) SendContext(ctx context.Context, m message.Composer) error {
+	if !b.shouldLog(m) {
+		return nil
+	}
+	start := time.Now()
+
+	// post the buffered messages first, so that this message does
+	// not overtake them.
+	if err := b.Flush(ctx); err != nil && ctx.Err() != nil {
+		return err
+	}
+
+	out, err := json.Marshal([][]interface{}{{float64(time.Now().Unix()), m.String()}})
+	if err != nil {
+		return err
+	}
+
+	if err = b.postLinesContext(ctx, bytes.NewBuffer(out)); err != nil {
+		return err
+	}
+
+	b.recordSend(m, start)
+	return nil
+}
+
 func (b *buildlogger) backgroundSender(stop <-chan struct{}, finished chan<- struct{}) {
 	buffer := [][]interface{}{}
 
@@ -282,6 +320,10 @@ func (b *buildlogger) backgroundSender(stop <-chan struct{}, finished chan<- str
 			b.sendMessages(buffer)
 			buffer = [][]interface{}{}
 			timer.Reset(b.conf.BufferInterval)
+		case done := <-b.flushes:
+			done <- b.sendMessages(buffer)
+			buffer = [][]interface{}{}
+			timer.Reset(b.conf.BufferInterval)
 		case <-stop:
 			b.sendMessages(buffer)
 			close(finished)
@@ -291,10 +333,11 @@ func (b *buildlogger) backgroundSender(stop <-chan struct{}, finished chan<- str
 
 }
 
-func (b *buildlogger) sendMessages(buffer [][]interface{}) {
+func (b *buildlogger) sendMessages(buffer [][]interface{}) error {
 	if len(buffer) == 0 {
-		return
+		return nil
 	}
+	defer b.stats.addQueued(-int64(len(buffer)))
 	out, err := json.Marshal(buffer)
 	if err != nil {
 		b.conf.Local.Send(message.NewErrorMessage(level.Error, err))
@@ -302,6 +345,30 @@ func (b *buildlogger) sendMessages(buffer [][]interface{}) {
 
 	if err := b.postLines(bytes.NewBuffer(out)); err != nil {
 		b.errHandler(err, message.NewBytesMessage(b.level.Default, out))
+		return err
+	}
+
+	return nil
+}
+
+// Flush posts the buffered messages to the buildlogger service, and
+// returns the error from posting them, if any.
+func (b *buildlogger) Flush(ctx context.Context) error {
+	done := make(chan error, 1)
+
+	select {
+	case b.flushes <- done:
+	case <-b.finished:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
 	}
 }
 
@@ -384,13 +451,27 @@ func (b *buildlogger) getURL() string {
 }
 
 func (b *buildlogger) postLines(body io.Reader) error {
+	return b.postLinesContext(context.Background(), body)
+}
+
+func (b *buildlogger) postLinesContext(ctx context.Context, body io.Reader) error {
 	req, err := http.NewRequest("POST", b.getURL(), body)
 
 	if err != nil {
 		return err
 	}
+	req = req.WithContext(ctx)
 	req.SetBasicAuth(b.conf.username, b.conf.password)
 
-	_, err = b.client.Do(req)
-	return err
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("buildlogger responded with %s", resp.Status)
+	}
+
+	return nil
 }
@@ -5,18 +5,19 @@ Call site loggers provide a way to record the line number and file
 name where the logging call was made, which is particularly useful in
 tracing down log messages.
 
-This sender does *not* attach this data to the Message object, and the
-call site information is only logged when formatting the message
-itself. Additionally the call site includes the file name and its
-enclosing directory.
+If the Journaler recorded the call site on the message (see the
+SetCallSite method of logging.Grip), or the message is a stack
+message, the sender logs that call site. Otherwise the call site
+information is only determined when formatting the message itself.
+The call site includes the file name and its enclosing directory.
 
 When constructing the Sender you must specify a "depth"
 argument This sets the offset for the call site relative to the
-Sender's Send() method. Grip's default logger (e.g. the grip.Info()
-methods and friends) requires a depth of 2, while in *most* other
-cases you will want to use a depth of 1. The LogMany, and
-Emergency[Panic,Fatal] methods also include an extra level of
-indirection.
+Sender's Send() method, for messages without a recorded call
+site. Grip's default logger (e.g. the grip.Info() methods and
+friends) requires a depth of 2, while in *most* other cases you will
+want to use a depth of 1. The LogMany, and Emergency[Panic,Fatal]
+methods also include an extra level of indirection.
 
 Create a call site logger with one of the following constructors:
 
@@ -0,0 +1,242 @@
+package send
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mongodb/grip/level"
+	"github.com/mongodb/grip/message"
+)
+
+// NewGitHubActionsLogger constructs a configured Sender that writes
+// messages to standard output as GitHub Actions workflow commands, so
+// that errors and warnings appear as annotations on the workflow run
+// and pull request. See MakeGitHubActionsFormatter.
+func NewGitHubActionsLogger(name string, l LevelInfo) (Sender, error) {
+	return setup(MakeGitHubActionsLogger(), name, l)
+}
+
+// MakeGitHubActionsLogger returns an unconfigured Sender that writes
+// messages to standard output as GitHub Actions workflow commands.
+func MakeGitHubActionsLogger() Sender {
+	return makeCILogger(MakeGitHubActionsFormatter())
+}
+
+// NewTeamCityLogger constructs a configured Sender that writes
+// messages to standard output as TeamCity service messages. See
+// MakeTeamCityFormatter.
+func NewTeamCityLogger(name string, l LevelInfo) (Sender, error) {
+	return setup(MakeTeamCityLogger(), name, l)
+}
+
+// MakeTeamCityLogger returns an unconfigured Sender that writes
+// messages to standard output as TeamCity service messages.
+func MakeTeamCityLogger() Sender {
+	return makeCILogger(MakeTeamCityFormatter())
+}
+
+func makeCILogger(fm MessageFormatter) Sender {
+	s := &nativeLogger{
+		Base:   NewBase(""),
+		logger: log.New(os.Stdout, "", 0),
+	}
+
+	_ = s.SetFormatter(fm)
+	_ = s.SetErrorHandler(ErrorHandlerFromLogger(s.logger))
+
+	return s
+}
+
+// MakeGitHubActionsFormatter returns a MessageFormatter that renders
+// messages as GitHub Actions workflow commands:
+//
+//     emergency, alert, critical, error: ::error file=<file>,line=<line>::<message>
+//     warning: ::warning file=<file>,line=<line>::<message>
+//     notice: ::notice file=<file>,line=<line>::<message>
+//     info: <message>
+//     debug, trace: ::debug::<message>
+//
+// The file and line are the call site of the message (see
+// message.GetCallSite), if known, relative to the GITHUB_WORKSPACE
+// directory (or the working directory). Group messages with more than
+// one message are rendered as a collapsible group, whose title is the
+// first message:
+//
+//     ::group::<first message>
+//     <other messages>
+//     ::endgroup::
+//
+// Messages (including info messages) and properties are escaped
+// according to the rules of workflow commands, so that messages
+// cannot contain commands. It can never error.
+func MakeGitHubActionsFormatter() MessageFormatter {
+	root := ciWorkspace("GITHUB_WORKSPACE")
+
+	return func(m message.Composer) (string, error) {
+		if msgs := ciGroupMessages(m); len(msgs) > 1 {
+			lines := []string{"::group::" + githubEscapeData(msgs[0].String())}
+			for _, msg := range msgs[1:] {
+				lines = append(lines, githubCommand(root, msg))
+			}
+			lines = append(lines, "::endgroup::")
+
+			return strings.Join(lines, "\n"), nil
+		}
+
+		return githubCommand(root, m), nil
+	}
+}
+
+func githubCommand(root string, m message.Composer) string {
+	var command string
+	switch p := m.Priority(); {
+	case p >= level.Error:
+		command = "error"
+	case p >= level.Warning:
+		command = "warning"
+	case p >= level.Notice:
+		command = "notice"
+	case p >= level.Info:
+		// info messages have no command, but are escaped so that
+		// lines in the message can't be run as commands.
+		return githubEscapeData(m.String())
+	default:
+		return "::debug::" + githubEscapeData(m.String())
+	}
+
+	if frame, ok := message.GetCallSite(m); ok && frame.File != "" {
+		return fmt.Sprintf("::%s file=%s,line=%d::%s", command,
+			githubEscapeProperty(ciRelativePath(root, frame.File)), frame.Line,
+			githubEscapeData(m.String()))
+	}
+
+	return fmt.Sprintf("::%s::%s", command, githubEscapeData(m.String()))
+}
+
+var (
+	githubDataEscaper     = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+	githubPropertyEscaper = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+)
+
+func githubEscapeData(str string) string     { return githubDataEscaper.Replace(str) }
+func githubEscapeProperty(str string) string { return githubPropertyEscaper.Replace(str) }
+
+// MakeTeamCityFormatter returns a MessageFormatter that renders
+// messages as TeamCity service messages:
+//
+//     ##teamcity[message text='<message>' status='<status>']
+//
+// The status is ERROR for messages at the error priority and above,
+// WARNING for warnings, and NORMAL otherwise. Error messages include
+// the stack trace of stack messages, or the call site of the message
+// (see message.GetCallSite), if known, as their error details. Group
+// messages with more than one message are rendered as a block, whose
+// name is the first message:
+//
+//     ##teamcity[blockOpened name='<first message>']
+//     <other messages>
+//     ##teamcity[blockClosed name='<first message>']
+//
+// Values are escaped according to the rules of service messages. It
+// can never error.
+func MakeTeamCityFormatter() MessageFormatter {
+	root := ciWorkspace("")
+
+	return func(m message.Composer) (string, error) {
+		if msgs := ciGroupMessages(m); len(msgs) > 1 {
+			name := teamCityEscape(msgs[0].String())
+			lines := []string{fmt.Sprintf("##teamcity[blockOpened name='%s']", name)}
+			for _, msg := range msgs[1:] {
+				lines = append(lines, teamCityMessage(root, msg))
+			}
+			lines = append(lines, fmt.Sprintf("##teamcity[blockClosed name='%s']", name))
+
+			return strings.Join(lines, "\n"), nil
+		}
+
+		return teamCityMessage(root, m), nil
+	}
+}
+
+func teamCityMessage(root string, m message.Composer) string {
+	status := "NORMAL"
+	switch p := m.Priority(); {
+	case p >= level.Error:
+		status = "ERROR"
+	case p >= level.Warning:
+		status = "WARNING"
+	}
+
+	out := fmt.Sprintf("##teamcity[message text='%s' status='%s'", teamCityEscape(m.String()), status)
+
+	if status == "ERROR" {
+		details := []string{}
+		if trace, ok := m.Raw().(message.StackTrace); ok {
+			for _, frame := range trace.Frames {
+				details = append(details, fmt.Sprintf("%s\n    %s:%d", frame.Function, ciRelativePath(root, frame.File), frame.Line))
+			}
+		} else if frame, ok := message.GetCallSite(m); ok && frame.File != "" {
+			details = append(details, fmt.Sprintf("%s:%d", ciRelativePath(root, frame.File), frame.Line))
+		}
+
+		if len(details) > 0 {
+			out += fmt.Sprintf(" errorDetails='%s'", teamCityEscape(strings.Join(details, "\n")))
+		}
+	}
+
+	return out + "]"
+}
+
+var teamCityEscaper = strings.NewReplacer("|", "||", "'", "|'", "\n", "|n", "\r", "|r", "[", "|[", "]", "|]")
+
+func teamCityEscape(str string) string { return teamCityEscaper.Replace(str) }
+
+// ciGroupMessages returns the loggable messages of group messages, and
+// nil for other messages.
+func ciGroupMessages(m message.Composer) []message.Composer {
+	group, ok := m.(*message.GroupComposer)
+	if !ok {
+		return nil
+	}
+
+	out := []message.Composer{}
+	for _, msg := range group.Messages() {
+		if msg.Loggable() {
+			out = append(out, msg)
+		}
+	}
+
+	return out
+}
+
+// ciWorkspace returns the directory that file paths in annotations are
+// relative to: the value of the environment variable, if set, and
+// otherwise the working directory.
+func ciWorkspace(env string) string {
+	if env != "" {
+		if dir := os.Getenv(env); dir != "" {
+			return dir
+		}
+	}
+
+	dir, _ := os.Getwd()
+	return dir
+}
+
+// ciRelativePath returns the path relative to the root, if it is
+// within the root, and otherwise the path.
+func ciRelativePath(root, path string) string {
+	if root == "" || !filepath.IsAbs(path) {
+		return path
+	}
+
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return path
+	}
+
+	return filepath.ToSlash(rel)
+}
@@ -0,0 +1,115 @@
+package send
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mongodb/grip/level"
+	"github.com/mongodb/grip/message"
+	"github.com/stretchr/testify/assert"
+)
+
+func withCallSite(m message.Composer, file string, line int) message.Composer {
+	m.(message.CallSiteComposer).SetCallSite(message.StackFrame{Function: "main.main", File: file, Line: line})
+	return m
+}
+
+func TestGitHubActionsFormatter(t *testing.T) {
+	assert := assert.New(t)
+
+	prev, ok := os.LookupEnv("GITHUB_WORKSPACE")
+	assert.NoError(os.Setenv("GITHUB_WORKSPACE", "/home/runner/work/repo"))
+	defer func() {
+		if ok {
+			_ = os.Setenv("GITHUB_WORKSPACE", prev)
+		} else {
+			_ = os.Unsetenv("GITHUB_WORKSPACE")
+		}
+	}()
+
+	fm := MakeGitHubActionsFormatter()
+	for expected, m := range map[string]message.Composer{
+		"::error file=cmd/main.go,line=12::build failed":    withCallSite(message.NewDefaultMessage(level.Critical, "build failed"), "/home/runner/work/repo/cmd/main.go", 12),
+		"::warning file=/other/a%2Cb%3A.go,line=3::careful": withCallSite(message.NewDefaultMessage(level.Warning, "careful"), "/other/a,b:.go", 3),
+		"::notice::100%25 done%0Anext line%0D":              message.NewDefaultMessage(level.Notice, "100% done\nnext line\r"),
+		"plain output: ::error::":                           message.NewDefaultMessage(level.Info, "plain output: ::error::"),
+		"line%0A::add-mask::secret%0D%0A::error::x":         message.NewDefaultMessage(level.Info, "line\n::add-mask::secret\r\n::error::x"),
+		"::debug::details":                                  withCallSite(message.NewDefaultMessage(level.Trace, "details"), "/home/runner/work/repo/main.go", 1),
+		"::error::it broke":                                 message.NewErrorMessage(level.Error, errors.New("it broke")),
+	} {
+		out, err := fm(m)
+		assert.NoError(err)
+		assert.Equal(expected, out)
+	}
+
+	out, err := fm(message.MakeGroupComposer(
+		message.NewDefaultMessage(level.Info, "running tests"),
+		message.NewDefaultMessage(level.Info, "ok"),
+		message.NewDefaultMessage(level.Info, ""),
+		message.NewDefaultMessage(level.Error, "test failed"),
+	))
+	assert.NoError(err)
+	assert.Equal("::group::running tests\nok\n::error::test failed\n::endgroup::", out)
+
+	// groups of one message are rendered as the message
+	out, err = fm(message.MakeGroupComposer(message.NewDefaultMessage(level.Warning, "alone")))
+	assert.NoError(err)
+	assert.Equal("::warning::alone", out)
+}
+
+func TestTeamCityFormatter(t *testing.T) {
+	assert := assert.New(t)
+
+	wd, err := os.Getwd()
+	assert.NoError(err)
+
+	fm := MakeTeamCityFormatter()
+	for expected, m := range map[string]message.Composer{
+		"##teamcity[message text='hello' status='NORMAL']":                              message.NewDefaultMessage(level.Info, "hello"),
+		"##teamcity[message text='it|'s |[bad|]|n|| |r' status='WARNING']":              message.NewDefaultMessage(level.Warning, "it's [bad]\n| \r"),
+		"##teamcity[message text='failed' status='ERROR' errorDetails='src/main.go:7']": withCallSite(message.NewDefaultMessage(level.Alert, "failed"), filepath.Join(wd, "src", "main.go"), 7),
+		"##teamcity[message text='failed' status='ERROR']":                              message.NewDefaultMessage(level.Error, "failed"),
+	} {
+		out, err := fm(m)
+		assert.NoError(err)
+		assert.Equal(expected, out)
+	}
+
+	stack := message.NewStack(1, "crashed")
+	assert.NoError(stack.SetPriority(level.Error))
+	out, err := fm(stack)
+	assert.NoError(err)
+	assert.True(strings.HasPrefix(out, "##teamcity[message text='|[send/ci_test.go:"), out)
+	assert.Contains(out, "errorDetails='github.com/mongodb/grip/send.TestTeamCityFormatter|n    ci_test.go:")
+
+	out, err = fm(message.MakeGroupComposer(
+		message.NewDefaultMessage(level.Info, "tests"),
+		message.NewDefaultMessage(level.Warning, "slow"),
+	))
+	assert.NoError(err)
+	assert.Equal("##teamcity[blockOpened name='tests']\n##teamcity[message text='slow' status='WARNING']\n##teamcity[blockClosed name='tests']", out)
+}
+
+func TestCIConfig(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, name := range []string{"github-actions", "teamcity"} {
+		s, err := BuildSender(&SenderConfig{Type: name, Name: "ci"})
+		assert.NoError(err)
+		assert.Equal("ci", s.Name())
+
+		_, ok := getFormatterFactory(name)
+		assert.True(ok)
+	}
+
+	s, err := NewGitHubActionsLogger("gha", LevelInfo{level.Info, level.Info})
+	assert.NoError(err)
+	assert.Equal("gha", s.Name())
+
+	s, err = NewTeamCityLogger("tc", LevelInfo{level.Info, level.Info})
+	assert.NoError(err)
+	assert.Equal("tc", s.Name())
+}
@@ -0,0 +1,191 @@
+package send
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mongodb/grip/level"
+	"github.com/mongodb/grip/message"
+)
+
+const (
+	colorReset      = "\x1b[0m"
+	colorBold       = "\x1b[1m"
+	colorDim        = "\x1b[2m"
+	colorRed        = "\x1b[31m"
+	colorGreen      = "\x1b[32m"
+	colorYellow     = "\x1b[33m"
+	colorBlue       = "\x1b[34m"
+	colorMagenta    = "\x1b[35m"
+	colorCyan       = "\x1b[36m"
+	colorGray       = "\x1b[90m"
+	colorWhiteOnRed = "\x1b[97;41m"
+
+	// colorLevelWidth is the length of the longest priority name,
+	// "emergency".
+	colorLevelWidth = 9
+)
+
+var priorityColors = map[level.Priority]string{
+	level.Emergency: colorBold + colorWhiteOnRed,
+	level.Alert:     colorBold + colorWhiteOnRed,
+	level.Critical:  colorBold + colorRed,
+	level.Error:     colorRed,
+	level.Warning:   colorYellow,
+	level.Notice:    colorCyan,
+	level.Info:      colorGreen,
+	level.Debug:     colorBlue,
+	level.Trace:     colorMagenta,
+}
+
+// NewColorConsoleLogger constructs a configured Sender that writes
+// colorized, human-friendly messages to standard output, for
+// developers who run services locally. See MakeColorFormatter for the
+// format of the messages.
+func NewColorConsoleLogger(name string, l LevelInfo) (Sender, error) {
+	return setup(MakeColorConsoleLogger(), name, l)
+}
+
+// MakeColorConsoleLogger returns an unconfigured Sender that writes
+// colorized messages to standard output. The Sender only uses colors
+// if standard output is a terminal, and the NO_COLOR environment
+// variable is not set, as determined by ColorEnabled.
+func MakeColorConsoleLogger() Sender {
+	color := ColorEnabled(os.Stdout)
+	s := &nativeLogger{
+		Base:   NewBase(""),
+		logger: log.New(os.Stdout, "", 0),
+	}
+
+	_ = s.SetFormatter(MakeColorFormatter(color))
+	_ = s.SetErrorHandler(ErrorHandlerFromLogger(s.logger))
+
+	s.reset = func() {
+		prefix := fmt.Sprintf("[%s] ", s.Name())
+		if color {
+			prefix = colorBold + prefix + colorReset
+		}
+		s.logger.SetPrefix(prefix)
+	}
+
+	return s
+}
+
+// ColorEnabled reports whether output to the file should use colors:
+// the file must be a terminal, the NO_COLOR environment variable
+// (see https://no-color.org) must be empty, and TERM must not be
+// "dumb".
+func ColorEnabled(f *os.File) bool {
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+
+	if f == nil {
+		return false
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// MakeColorFormatter returns a MessageFormatter that renders messages
+// for people to read in a terminal, in the following format:
+//
+//    <time> <PRIORITY> <message> <key>=<value> ...
+//
+// The priority names are aligned, and, if color is true, colored
+// according to the priority. The fields of message.Fields messages
+// follow the message, sorted by key and dimmed, and the frames of
+// stack messages follow the message on separate, indented lines. It
+// can never error.
+func MakeColorFormatter(color bool) MessageFormatter {
+	return func(m message.Composer) (string, error) {
+		paint := func(code, str string) string {
+			if !color || code == "" {
+				return str
+			}
+			return code + str + colorReset
+		}
+
+		raw := m.Raw()
+		ts := time.Now()
+		msg := m.String()
+		var fields message.Fields
+		var frames []message.StackFrame
+
+		switch val := raw.(type) {
+		case message.Fields:
+			fields = val
+			if t, ok := val["time"].(time.Time); ok {
+				ts = t
+			}
+			msg = ""
+			if v, ok := val["msg"]; ok && v != nil {
+				msg = fmt.Sprint(v)
+			}
+		case message.StackTrace:
+			frames = val.Frames
+			if !val.Time.IsZero() {
+				ts = val.Time
+			}
+			msg = val.Message
+		}
+
+		priority := strings.ToUpper(m.Priority().String())
+		priority += strings.Repeat(" ", colorLevelWidth-len(priority))
+
+		buf := &bytes.Buffer{}
+		buf.WriteString(paint(colorGray, ts.Format("15:04:05.000")))
+		buf.WriteByte(' ')
+		buf.WriteString(paint(priorityColors[m.Priority()], priority))
+		buf.WriteByte(' ')
+		buf.WriteString(msg)
+
+		if len(fields) > 0 {
+			keys := make([]string, 0, len(fields))
+			for k := range fields {
+				switch k {
+				case "time", "msg":
+					continue
+				}
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+
+			pairs := make([]string, 0, len(keys))
+			for _, k := range keys {
+				value := logfmtValue(fields[k])
+				if logfmtNeedsQuotes(value) {
+					value = strconv.Quote(value)
+				}
+				pairs = append(pairs, logfmtKey(k)+"="+value)
+			}
+
+			if len(pairs) > 0 {
+				if msg != "" {
+					buf.WriteByte(' ')
+				}
+				buf.WriteString(paint(colorDim, strings.Join(pairs, " ")))
+			}
+		}
+
+		for _, frame := range frames {
+			buf.WriteString("\n    ")
+			buf.WriteString(frame.Function)
+			buf.WriteString("\n        ")
+			buf.WriteString(paint(colorDim, fmt.Sprintf("%s:%d", frame.File, frame.Line)))
+		}
+
+		return buf.String(), nil
+	}
+}
@@ -0,0 +1,113 @@
+package send
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mongodb/grip/level"
+	"github.com/mongodb/grip/message"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestColorFormatter(t *testing.T) {
+	assert := assert.New(t)
+	ts := time.Date(2018, time.March, 4, 5, 6, 7, 0, time.Local)
+
+	fm := MakeColorFormatter(false)
+	out, err := fm(message.NewFieldsMessage(level.Warning, "disk is full", message.Fields{
+		"time": ts,
+		"path": "/var/lib/data",
+		"user": "jane doe",
+	}))
+	assert.NoError(err)
+	assert.Equal(`05:06:07.000 WARNING   disk is full path=/var/lib/data user="jane doe"`, out)
+
+	out, err = fm(message.NewFields(level.Emergency, message.Fields{"time": ts, "n": 1}))
+	assert.NoError(err)
+	assert.Equal("05:06:07.000 EMERGENCY n=1", out)
+
+	out, err = fm(message.NewDefaultMessage(level.Info, "hello"))
+	assert.NoError(err)
+	assert.True(strings.HasSuffix(out, " INFO      hello"), out)
+	assert.NotContains(out, "\x1b[")
+
+	m := message.NewStack(1, "stacked")
+	assert.NoError(m.SetPriority(level.Debug))
+	out, err = fm(m)
+	assert.NoError(err)
+	lines := strings.Split(out, "\n")
+	if assert.True(len(lines) > 3, out) {
+		assert.Contains(lines[0], "DEBUG")
+		assert.Contains(lines[0], "stacked")
+		assert.Equal("    github.com/mongodb/grip/send.TestColorFormatter", lines[1])
+		assert.True(strings.HasPrefix(lines[2], "        "))
+		assert.Contains(lines[2], "color_test.go:")
+	}
+}
+
+func TestColorFormatterColors(t *testing.T) {
+	assert := assert.New(t)
+	ts := time.Date(2018, time.March, 4, 5, 6, 7, 0, time.Local)
+
+	fm := MakeColorFormatter(true)
+	out, err := fm(message.NewFieldsMessage(level.Error, "failed", message.Fields{"time": ts, "code": 2}))
+	assert.NoError(err)
+	assert.Equal(colorGray+"05:06:07.000"+colorReset+" "+colorRed+"ERROR    "+colorReset+" failed "+colorDim+"code=2"+colorReset, out)
+
+	for _, p := range []level.Priority{level.Emergency, level.Alert, level.Critical, level.Error,
+		level.Warning, level.Notice, level.Info, level.Debug, level.Trace} {
+		out, err = fm(message.NewDefaultMessage(p, "hello"))
+		assert.NoError(err)
+		assert.Contains(out, priorityColors[p]+strings.ToUpper(p.String()))
+	}
+}
+
+func TestColorEnabled(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.False(ColorEnabled(nil))
+
+	f, err := ioutil.TempFile("", "grip-color")
+	assert.NoError(err)
+	defer os.Remove(f.Name())
+	defer f.Close()
+	assert.False(ColorEnabled(f))
+
+	prev, ok := os.LookupEnv("NO_COLOR")
+	assert.NoError(os.Setenv("NO_COLOR", "1"))
+	defer func() {
+		if ok {
+			_ = os.Setenv("NO_COLOR", prev)
+		} else {
+			_ = os.Unsetenv("NO_COLOR")
+		}
+	}()
+	assert.False(ColorEnabled(os.Stdout))
+}
+
+func TestColorConsoleLoggerConfig(t *testing.T) {
+	assert := assert.New(t)
+
+	s, err := NewColorConsoleLogger("color", LevelInfo{level.Info, level.Info})
+	assert.NoError(err)
+	assert.Equal("color", s.Name())
+
+	s, err = BuildSender(&SenderConfig{Type: "color-console", Name: "app", Options: map[string]interface{}{"color": "always"}})
+	assert.NoError(err)
+	assert.Equal("app", s.Name())
+
+	_, err = BuildSender(&SenderConfig{Type: "color-console", Options: map[string]interface{}{"color": "sometimes"}})
+	assert.Error(err)
+
+	factory, ok := getFormatterFactory("color")
+	if assert.True(ok) {
+		fm, err := factory(&FormatterConfig{Type: "color", Options: map[string]interface{}{"color": "never"}})
+		assert.NoError(err)
+		out, err := fm(message.NewDefaultMessage(level.Info, "hello"))
+		assert.NoError(err)
+		assert.NotContains(out, "\x1b[")
+	}
+}
@@ -0,0 +1,526 @@
+package send
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mongodb/grip/level"
+	"github.com/mongodb/grip/message"
+)
+
+// SenderConfig describes a tree of Senders, so that applications can
+// build their logging configuration from a JSON or YAML document
+// rather than in code. Each SenderConfig describes one Sender: its
+// type, which must be registered with RegisterSenderType, and the
+// configuration common to all Senders. Type-specific options are in
+// Options, and the Senders that a multi Sender sends to, or that
+// wrappers (e.g. the buffered Sender) wrap, are in Senders.
+//
+// Use ParseSenderConfig to read a configuration, and BuildSender to
+// construct the Senders.
+type SenderConfig struct {
+	Type string `bson:"type" json:"type" yaml:"type"`
+
+	// Name is the name of the Sender. Senders without a name use
+	// the name of the Sender that contains them.
+	Name string `bson:"name,omitempty" json:"name,omitempty" yaml:"name,omitempty"`
+
+	// Threshold and Default are the names of the Sender's
+	// threshold and default priorities (e.g. "info".) Senders
+	// that do not specify these levels use the levels of the
+	// Sender that contains them, or "info" and "notice".
+	Threshold string `bson:"threshold,omitempty" json:"threshold,omitempty" yaml:"threshold,omitempty"`
+	Default   string `bson:"default,omitempty" json:"default,omitempty" yaml:"default,omitempty"`
+
+	// Formatter, if specified, replaces the Sender's formatter.
+	Formatter *FormatterConfig `bson:"formatter,omitempty" json:"formatter,omitempty" yaml:"formatter,omitempty"`
+
+	Options map[string]interface{} `bson:"options,omitempty" json:"options,omitempty" yaml:"options,omitempty"`
+	Senders []SenderConfig         `bson:"senders,omitempty" json:"senders,omitempty" yaml:"senders,omitempty"`
+
+	// Routes configures the routes of "router" Senders, whose
+	// Senders receive the messages that match no route.
+	Routes []RouteConfig `bson:"routes,omitempty" json:"routes,omitempty" yaml:"routes,omitempty"`
+}
+
+// FormatterConfig describes a MessageFormatter. The type must be
+// registered with RegisterFormatterType.
+type FormatterConfig struct {
+	Type    string                 `bson:"type" json:"type" yaml:"type"`
+	Options map[string]interface{} `bson:"options,omitempty" json:"options,omitempty" yaml:"options,omitempty"`
+}
+
+// RouteConfig describes a Route for a router Sender. Priorities are
+// specified by name.
+type RouteConfig struct {
+	MinPriority string                 `bson:"min_priority,omitempty" json:"min_priority,omitempty" yaml:"min_priority,omitempty"`
+	MaxPriority string                 `bson:"max_priority,omitempty" json:"max_priority,omitempty" yaml:"max_priority,omitempty"`
+	Names       []string               `bson:"names,omitempty" json:"names,omitempty" yaml:"names,omitempty"`
+	Fields      map[string]interface{} `bson:"fields,omitempty" json:"fields,omitempty" yaml:"fields,omitempty"`
+	Types       []string               `bson:"types,omitempty" json:"types,omitempty" yaml:"types,omitempty"`
+	Continue    bool                   `bson:"continue,omitempty" json:"continue,omitempty" yaml:"continue,omitempty"`
+	Senders     []SenderConfig         `bson:"senders" json:"senders" yaml:"senders"`
+}
+
+// SenderFactory constructs a Sender from its configuration. The
+// senders are the Senders built from the configuration's Senders, in
+// order. Factories do not need to configure the name, levels, or
+// formatter of the Sender, which BuildSender does, and should use
+// DecodeOptions to read their options.
+type SenderFactory func(conf *SenderConfig, senders []Sender) (Sender, error)
+
+// FormatterFactory constructs a MessageFormatter from its
+// configuration.
+type FormatterFactory func(conf *FormatterConfig) (MessageFormatter, error)
+
+var configRegistry = struct {
+	senders    map[string]SenderFactory
+	formatters map[string]FormatterFactory
+	mutex      sync.RWMutex
+}{
+	senders:    map[string]SenderFactory{},
+	formatters: map[string]FormatterFactory{},
+}
+
+// RegisterSenderType makes a Sender type available to BuildSender,
+// so that packages that implement Senders can make them configurable.
+// Returns an error if the name is empty or already registered, or if
+// the factory is nil.
+func RegisterSenderType(name string, factory SenderFactory) error {
+	if name == "" || factory == nil {
+		return errors.New("sender types must have a name and a factory")
+	}
+
+	configRegistry.mutex.Lock()
+	defer configRegistry.mutex.Unlock()
+
+	if _, ok := configRegistry.senders[name]; ok {
+		return fmt.Errorf("sender type '%s' is already registered", name)
+	}
+
+	configRegistry.senders[name] = factory
+	return nil
+}
+
+// RegisterFormatterType makes a formatter type available to
+// BuildSender. Returns an error if the name is empty or already
+// registered, or if the factory is nil.
+func RegisterFormatterType(name string, factory FormatterFactory) error {
+	if name == "" || factory == nil {
+		return errors.New("formatter types must have a name and a factory")
+	}
+
+	configRegistry.mutex.Lock()
+	defer configRegistry.mutex.Unlock()
+
+	if _, ok := configRegistry.formatters[name]; ok {
+		return fmt.Errorf("formatter type '%s' is already registered", name)
+	}
+
+	configRegistry.formatters[name] = factory
+	return nil
+}
+
+// SenderTypes returns the names of the registered Sender types, in
+// alphabetical order.
+func SenderTypes() []string {
+	configRegistry.mutex.RLock()
+	defer configRegistry.mutex.RUnlock()
+
+	out := make([]string, 0, len(configRegistry.senders))
+	for name := range configRegistry.senders {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+
+	return out
+}
+
+func getSenderFactory(name string) (SenderFactory, bool) {
+	configRegistry.mutex.RLock()
+	defer configRegistry.mutex.RUnlock()
+
+	f, ok := configRegistry.senders[name]
+	return f, ok
+}
+
+func getFormatterFactory(name string) (FormatterFactory, bool) {
+	configRegistry.mutex.RLock()
+	defer configRegistry.mutex.RUnlock()
+
+	f, ok := configRegistry.formatters[name]
+	return f, ok
+}
+
+// ParseSenderConfig reads a SenderConfig using the unmarshal function,
+// which should have the signature of json.Unmarshal, and validates
+// it. If unmarshal is nil, ParseSenderConfig reads JSON. To read YAML,
+// pass the Unmarshal function from a YAML package, for example:
+//
+//    conf, err := send.ParseSenderConfig(data, yaml.Unmarshal)
+func ParseSenderConfig(data []byte, unmarshal func([]byte, interface{}) error) (*SenderConfig, error) {
+	if unmarshal == nil {
+		unmarshal = json.Unmarshal
+	}
+
+	conf := &SenderConfig{}
+	if err := unmarshal(data, conf); err != nil {
+		return nil, fmt.Errorf("problem parsing sender configuration: %s", err.Error())
+	}
+
+	if err := conf.Validate(); err != nil {
+		return nil, err
+	}
+
+	return conf, nil
+}
+
+// Validate checks the configuration of the tree of Senders, and
+// returns an error that lists each problem with the path to the
+// offending configuration (e.g. "$.senders[1].threshold".) Validate
+// does not check type-specific options, which are checked when the
+// Senders are built.
+func (c *SenderConfig) Validate() error {
+	errs := []string{}
+	c.validate("$", &errs)
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "\n"))
+	}
+
+	return nil
+}
+
+func (c *SenderConfig) validate(path string, errs *[]string) {
+	report := func(field, msg string, args ...interface{}) {
+		*errs = append(*errs, fmt.Sprintf("%s%s: %s", path, field, fmt.Sprintf(msg, args...)))
+	}
+
+	if c.Type == "" {
+		report(".type", "sender type is required")
+	} else if _, ok := getSenderFactory(c.Type); !ok {
+		report(".type", "unknown sender type '%s'", c.Type)
+	}
+
+	if c.Threshold != "" && level.FromString(c.Threshold) == level.Invalid {
+		report(".threshold", "'%s' is not a valid priority", c.Threshold)
+	}
+
+	if c.Default != "" && level.FromString(c.Default) == level.Invalid {
+		report(".default", "'%s' is not a valid priority", c.Default)
+	}
+
+	if c.Formatter != nil {
+		if c.Formatter.Type == "" {
+			report(".formatter.type", "formatter type is required")
+		} else if _, ok := getFormatterFactory(c.Formatter.Type); !ok {
+			report(".formatter.type", "unknown formatter type '%s'", c.Formatter.Type)
+		}
+	}
+
+	if len(c.Routes) > 0 && c.Type != "router" {
+		report(".routes", "only router senders have routes")
+	}
+
+	for idx, r := range c.Routes {
+		rpath := fmt.Sprintf("%s.routes[%d]", path, idx)
+
+		if r.MinPriority != "" && level.FromString(r.MinPriority) == level.Invalid {
+			*errs = append(*errs, fmt.Sprintf("%s.min_priority: '%s' is not a valid priority", rpath, r.MinPriority))
+		}
+
+		if r.MaxPriority != "" && level.FromString(r.MaxPriority) == level.Invalid {
+			*errs = append(*errs, fmt.Sprintf("%s.max_priority: '%s' is not a valid priority", rpath, r.MaxPriority))
+		}
+
+		if len(r.Senders) == 0 {
+			*errs = append(*errs, fmt.Sprintf("%s.senders: route must have at least one sender", rpath))
+		}
+
+		for sidx := range r.Senders {
+			r.Senders[sidx].validate(fmt.Sprintf("%s.senders[%d]", rpath, sidx), errs)
+		}
+	}
+
+	for idx := range c.Senders {
+		c.Senders[idx].validate(fmt.Sprintf("%s.senders[%d]", path, idx), errs)
+	}
+}
+
+// DecodeOptions decodes the configuration's options into the value
+// pointed to by out, typically a struct with json tags, and returns an
+// error if the options include keys that the struct does not define.
+func (c *SenderConfig) DecodeOptions(out interface{}) error {
+	return decodeConfigOptions(c.Options, out)
+}
+
+// DecodeOptions decodes the formatter's options into the value
+// pointed to by out. See SenderConfig.DecodeOptions.
+func (c *FormatterConfig) DecodeOptions(out interface{}) error {
+	return decodeConfigOptions(c.Options, out)
+}
+
+func decodeConfigOptions(opts map[string]interface{}, out interface{}) error {
+	data, err := json.Marshal(normalizeConfigValue(opts))
+	if err != nil {
+		return err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	return dec.Decode(out)
+}
+
+// normalizeConfigValue converts the map[interface{}]interface{}
+// values that some YAML packages produce into values that
+// encoding/json can marshal.
+func normalizeConfigValue(in interface{}) interface{} {
+	switch v := in.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			out[k] = normalizeConfigValue(val)
+		}
+		return out
+	case map[interface{}]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			out[fmt.Sprint(k)] = normalizeConfigValue(val)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for idx, val := range v {
+			out[idx] = normalizeConfigValue(val)
+		}
+		return out
+	default:
+		return in
+	}
+}
+
+// BuildSender validates the configuration and constructs the tree of
+// Senders that it describes. Errors identify the path to the
+// offending configuration. If building any Sender fails, BuildSender
+// closes the Senders that it has already built.
+//
+// Note that Journaler.SetSender replaces the levels of the Sender
+// with those of the Journaler.
+func BuildSender(conf *SenderConfig) (Sender, error) {
+	if conf == nil {
+		return nil, errors.New("sender configuration cannot be nil")
+	}
+
+	if err := conf.Validate(); err != nil {
+		return nil, err
+	}
+
+	return conf.build("$", "", LevelInfo{Default: level.Notice, Threshold: level.Info})
+}
+
+// LevelInfo returns the levels of the root of the tree of Senders: the
+// configured levels, or the "info" threshold and "notice" default.
+func (c *SenderConfig) LevelInfo() LevelInfo {
+	l := LevelInfo{Default: level.Notice, Threshold: level.Info}
+
+	if c.Threshold != "" {
+		l.Threshold = level.FromString(c.Threshold)
+	}
+
+	if c.Default != "" {
+		l.Default = level.FromString(c.Default)
+	}
+
+	return l
+}
+
+func (c *SenderConfig) build(path, name string, l LevelInfo) (Sender, error) {
+	if c.Name != "" {
+		name = c.Name
+	}
+
+	if c.Threshold != "" {
+		l.Threshold = level.FromString(c.Threshold)
+	}
+
+	if c.Default != "" {
+		l.Default = level.FromString(c.Default)
+	}
+
+	built := []Sender{}
+	closeBuilt := func() {
+		for _, s := range built {
+			_ = s.Close()
+		}
+	}
+
+	senders, err := buildSenderConfigs(fmt.Sprintf("%s.senders", path), c.Senders, name, l)
+	if err != nil {
+		return nil, err
+	}
+	built = append(built, senders...)
+
+	routes := make([]Route, 0, len(c.Routes))
+	for idx, r := range c.Routes {
+		rpath := fmt.Sprintf("%s.routes[%d]", path, idx)
+		rsenders, err := buildSenderConfigs(rpath+".senders", r.Senders, name, l)
+		if err != nil {
+			closeBuilt()
+			return nil, err
+		}
+		built = append(built, rsenders...)
+
+		routes = append(routes, Route{
+			MinPriority: level.FromString(r.MinPriority),
+			MaxPriority: level.FromString(r.MaxPriority),
+			Names:       r.Names,
+			Fields:      message.Fields(r.Fields),
+			Types:       r.Types,
+			Continue:    r.Continue,
+			Senders:     rsenders,
+		})
+	}
+
+	factory, _ := getSenderFactory(c.Type)
+
+	var s Sender
+	if c.Type == "router" {
+		s, err = MakeRouterSender(senders, routes...)
+	} else {
+		s, err = factory(c, senders)
+	}
+	if err != nil {
+		closeBuilt()
+		return nil, fmt.Errorf("%s: %s", path, err.Error())
+	}
+
+	// Senders that contain other Senders only take the name and
+	// levels that they specify, because setting them may modify
+	// the Senders they contain.
+	if len(built) == 0 || c.Name != "" {
+		s.SetName(name)
+	}
+
+	if len(built) == 0 || c.Threshold != "" || c.Default != "" {
+		// setting the level of a Sender may set the levels of the
+		// Senders that it contains, so restore the levels that
+		// they were built with.
+		levels := memberLevels(s)
+		if err := s.SetLevel(l); err != nil {
+			_ = s.Close()
+			return nil, fmt.Errorf("%s: %s", path, err.Error())
+		}
+		for _, ml := range levels {
+			_ = ml.sender.SetLevel(ml.level)
+		}
+	}
+
+	if c.Formatter != nil {
+		ffactory, _ := getFormatterFactory(c.Formatter.Type)
+		fmtr, err := ffactory(c.Formatter)
+		if err == nil {
+			err = s.SetFormatter(fmtr)
+		}
+
+		if err != nil {
+			_ = s.Close()
+			return nil, fmt.Errorf("%s.formatter: %s", path, err.Error())
+		}
+	}
+
+	return s, nil
+}
+
+func buildSenderConfigs(path string, confs []SenderConfig, name string, l LevelInfo) ([]Sender, error) {
+	out := make([]Sender, 0, len(confs))
+	for idx := range confs {
+		s, err := confs[idx].build(fmt.Sprintf("%s[%d]", path, idx), name, l)
+		if err != nil {
+			for _, built := range out {
+				_ = built.Close()
+			}
+			return nil, err
+		}
+
+		out = append(out, s)
+	}
+
+	return out, nil
+}
+
+type memberLevel struct {
+	sender Sender
+	level  LevelInfo
+}
+
+// memberLevels returns the levels of the Senders that the Sender
+// contains, with each Sender before the Senders that it contains.
+func memberLevels(s Sender) []memberLevel {
+	out := []memberLevel{}
+	for _, m := range Members(s) {
+		out = append(out, memberLevel{sender: m, level: m.Level()})
+		out = append(out, memberLevels(m)...)
+	}
+
+	return out
+}
+
+// configDuration is a time.Duration that configuration options can
+// specify as a string (e.g. "10s"), or as a number of nanoseconds.
+type configDuration time.Duration
+
+func (d *configDuration) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err == nil {
+		dur, err := time.ParseDuration(str)
+		if err != nil {
+			return fmt.Errorf("'%s' is not a valid duration", str)
+		}
+		*d = configDuration(dur)
+		return nil
+	}
+
+	var num int64
+	if err := json.Unmarshal(data, &num); err != nil {
+		return fmt.Errorf("'%s' is not a valid duration", string(data))
+	}
+	*d = configDuration(num)
+
+	return nil
+}
+
+// configPriority is a level.Priority that configuration options can
+// specify by name or by number.
+type configPriority level.Priority
+
+func (p *configPriority) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err == nil {
+		if num, err := strconv.Atoi(str); err == nil {
+			str = level.Priority(num).String()
+		}
+
+		pri := level.FromString(str)
+		if pri == level.Invalid {
+			return fmt.Errorf("'%s' is not a valid priority", str)
+		}
+		*p = configPriority(pri)
+		return nil
+	}
+
+	var num int16
+	if err := json.Unmarshal(data, &num); err != nil || !level.IsValidPriority(level.Priority(num)) {
+		return fmt.Errorf("'%s' is not a valid priority", string(data))
+	}
+	*p = configPriority(num)
+
+	return nil
+}
@@ -0,0 +1,35 @@
+//go:build linux || freebsd || solaris || darwin
+// +build linux freebsd solaris darwin
+
+package send
+
+import "errors"
+
+func init() {
+	if err := RegisterSenderType("syslog", buildSyslogSender); err != nil {
+		panic(err)
+	}
+}
+
+// buildSyslogSender connects to the syslog service at the configured
+// network address, or to the local syslog service if the address is
+// not specified.
+func buildSyslogSender(conf *SenderConfig, senders []Sender) (Sender, error) {
+	if len(senders) != 0 {
+		return nil, errors.New("syslog senders do not wrap other senders")
+	}
+
+	opts := struct {
+		Network string `json:"network"`
+		Address string `json:"address"`
+	}{}
+	if err := conf.DecodeOptions(&opts); err != nil {
+		return nil, err
+	}
+
+	if (opts.Network == "") != (opts.Address == "") {
+		return nil, errors.New("must specify both network and address, or neither")
+	}
+
+	return MakeSysLogger(opts.Network, opts.Address), nil
+}
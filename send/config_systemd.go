@@ -0,0 +1,18 @@
+//go:build linux
+// +build linux
+
+package send
+
+func init() {
+	if err := RegisterSenderType("systemd", buildSystemdSender); err != nil {
+		panic(err)
+	}
+}
+
+func buildSystemdSender(conf *SenderConfig, senders []Sender) (Sender, error) {
+	if err := noSenders(conf, senders); err != nil {
+		return nil, err
+	}
+
+	return MakeSystemdLogger(), nil
+}
@@ -0,0 +1,252 @@
+package send
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mongodb/grip/level"
+	"github.com/mongodb/grip/message"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSenderConfigReportsPaths(t *testing.T) {
+	assert := assert.New(t)
+
+	conf, err := ParseSenderConfig([]byte(`{
+		"type": "multi",
+		"threshold": "loud",
+		"senders": [
+			{"type": "native"},
+			{"type": "router", "routes": [
+				{"min_priority": "error", "senders": [{"type": "carrier-pigeon"}]},
+				{"max_priority": "debug", "senders": []}
+			]},
+			{"type": "file", "formatter": {"type": "fancy"}}
+		]
+	}`), nil)
+	assert.Nil(conf)
+	assert.Error(err)
+
+	errs := strings.Split(err.Error(), "\n")
+	assert.Equal([]string{
+		"$.threshold: 'loud' is not a valid priority",
+		"$.senders[1].routes[0].senders[0].type: unknown sender type 'carrier-pigeon'",
+		"$.senders[1].routes[1].senders: route must have at least one sender",
+		"$.senders[2].formatter.type: unknown formatter type 'fancy'",
+	}, errs)
+
+	_, err = ParseSenderConfig([]byte(`{"type": `), nil)
+	assert.Error(err)
+
+	_, err = BuildSender(nil)
+	assert.Error(err)
+}
+
+func TestBuildSenderReportsFactoryErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	for path, conf := range map[string]*SenderConfig{
+		"$.senders[0]: must specify a path": {
+			Type:    "multi",
+			Senders: []SenderConfig{{Type: "file"}},
+		},
+		"$.senders[1]: buffered senders must wrap exactly one sender": {
+			Type:    "multi",
+			Senders: []SenderConfig{{Type: "native"}, {Type: "buffered"}},
+		},
+		"$.senders[0]: json: unknown field \"colour\"": {
+			Type: "buffered",
+			Senders: []SenderConfig{{
+				Type:    "native",
+				Options: map[string]interface{}{"colour": "red"},
+			}},
+		},
+		"$: 'sometimes' is not a valid duration": {
+			Type:    "dedup",
+			Options: map[string]interface{}{"window": "sometimes"},
+			Senders: []SenderConfig{{Type: "native"}},
+		},
+	} {
+		s, err := BuildSender(conf)
+		assert.Nil(s)
+		if assert.Error(err) {
+			assert.Contains(err.Error(), path)
+		}
+	}
+}
+
+func TestBuildSenderTree(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "grip-config")
+	assert.NoError(err)
+	defer os.RemoveAll(dir)
+
+	data, err := json.Marshal(map[string]interface{}{
+		"type":      "router",
+		"name":      "service",
+		"threshold": "debug",
+		"senders": []interface{}{
+			map[string]interface{}{
+				"type":      "file",
+				"formatter": map[string]interface{}{"type": "plain"},
+				"options":   map[string]interface{}{"path": filepath.Join(dir, "all.log")},
+			},
+		},
+		"routes": []interface{}{
+			map[string]interface{}{
+				"min_priority": "error",
+				"continue":     true,
+				"senders": []interface{}{
+					map[string]interface{}{
+						"type":    "buffered",
+						"options": map[string]interface{}{"count": 10, "interval": "1m"},
+						"senders": []interface{}{
+							map[string]interface{}{
+								"type":      "json-file",
+								"name":      "errors",
+								"threshold": "error",
+								"options":   map[string]interface{}{"path": filepath.Join(dir, "errors.log")},
+							},
+						},
+					},
+				},
+			},
+		},
+	})
+	assert.NoError(err)
+
+	conf, err := ParseSenderConfig(data, nil)
+	assert.NoError(err)
+
+	s, err := BuildSender(conf)
+	assert.NoError(err)
+	assert.Equal("service", s.Name())
+	assert.Equal(level.Debug, s.Level().Threshold)
+
+	tree := CollectStats(s)
+	assert.Len(tree.Children, 2)
+
+	s.Send(message.NewDefaultMessage(level.Debug, "debugging"))
+	s.Send(message.NewDefaultMessage(level.Error, "failing"))
+	assert.NoError(Flush(context.Background(), s))
+	assert.NoError(s.Close())
+
+	all, err := ioutil.ReadFile(filepath.Join(dir, "all.log"))
+	assert.NoError(err)
+	assert.Contains(string(all), "debugging")
+	assert.NotContains(string(all), "failing")
+
+	errs, err := ioutil.ReadFile(filepath.Join(dir, "errors.log"))
+	assert.NoError(err)
+	assert.Contains(string(errs), "failing")
+	assert.NotContains(string(errs), "debugging")
+}
+
+func TestBuildSenderRoutesByNumericFields(t *testing.T) {
+	assert := assert.New(t)
+
+	sink := MakeInternalLogger()
+	assert.NoError(RegisterSenderType("internal-test", func(_ *SenderConfig, _ []Sender) (Sender, error) {
+		return sink, nil
+	}))
+	defer func() {
+		configRegistry.mutex.Lock()
+		delete(configRegistry.senders, "internal-test")
+		configRegistry.mutex.Unlock()
+	}()
+
+	// JSON decodes numbers as float64
+	conf, err := ParseSenderConfig([]byte(`{
+		"type": "router",
+		"routes": [{"fields": {"status": 500}, "senders": [{"type": "internal-test"}]}]
+	}`), nil)
+	assert.NoError(err)
+
+	s, err := BuildSender(conf)
+	assert.NoError(err)
+
+	s.Send(message.NewFields(level.Info, message.Fields{"msg": "ok", "status": 200}))
+	s.Send(message.NewFields(level.Info, message.Fields{"msg": "failed", "status": 500}))
+	s.Send(message.NewFields(level.Info, message.Fields{"msg": "text", "status": "500"}))
+	if assert.Equal(1, sink.Len()) {
+		assert.Equal("failed", sink.GetMessage().Message.Raw().(message.Fields)["msg"])
+	}
+}
+
+func TestBuildSenderKeepsNestedThresholds(t *testing.T) {
+	assert := assert.New(t)
+
+	conf, err := ParseSenderConfig([]byte(`{
+		"type": "multi",
+		"threshold": "debug",
+		"senders": [
+			{"type": "native", "threshold": "error"},
+			{"type": "buffered", "senders": [{"type": "native", "threshold": "warning"}]},
+			{"type": "native"}
+		]
+	}`), nil)
+	assert.NoError(err)
+
+	s, err := BuildSender(conf)
+	assert.NoError(err)
+	defer s.Close()
+
+	assert.Equal(level.Debug, s.Level().Threshold)
+	members := Members(s)
+	if assert.Len(members, 3) {
+		assert.Equal(level.Error, members[0].Level().Threshold)
+		assert.Equal(level.Warning, members[1].Level().Threshold)
+		assert.Equal(level.Warning, Members(members[1])[0].Level().Threshold)
+		assert.Equal(level.Debug, members[2].Level().Threshold)
+	}
+}
+
+func TestBuildSenderFromNestedMaps(t *testing.T) {
+	assert := assert.New(t)
+
+	// YAML packages decode nested maps as map[interface{}]interface{}
+	conf := &SenderConfig{
+		Type: "enrich",
+		Options: map[string]interface{}{
+			"fields": map[interface{}]interface{}{"service": "api", 1: "one"},
+		},
+		Senders: []SenderConfig{{Type: "internal-test"}},
+	}
+
+	sink := MakeInternalLogger()
+	assert.NoError(RegisterSenderType("internal-test", func(_ *SenderConfig, _ []Sender) (Sender, error) {
+		return sink, nil
+	}))
+	defer func() {
+		configRegistry.mutex.Lock()
+		delete(configRegistry.senders, "internal-test")
+		configRegistry.mutex.Unlock()
+	}()
+
+	s, err := BuildSender(conf)
+	assert.NoError(err)
+
+	s.Send(message.NewFields(level.Info, message.Fields{"msg": "hello"}))
+	fields := sink.GetMessage().Message.Raw().(message.Fields)
+	assert.Equal("api", fields["service"])
+	assert.Equal("one", fields["1"])
+}
+
+func TestRegisterSenderType(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Error(RegisterSenderType("", buildNativeSender))
+	assert.Error(RegisterSenderType("example", nil))
+	assert.Error(RegisterSenderType("native", buildNativeSender))
+	assert.Error(RegisterFormatterType("json", buildCallSiteFormatter))
+
+	assert.Contains(SenderTypes(), "multi")
+	assert.Contains(SenderTypes(), "circuit-breaker")
+	assert.NotContains(SenderTypes(), "example")
+}
@@ -0,0 +1,750 @@
+package send
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"time"
+
+	"github.com/mongodb/grip/level"
+	"github.com/mongodb/grip/message"
+)
+
+// The built-in Sender types are registered with the following names.
+// Types that wrap other Senders (e.g. "buffered") require exactly one
+// Sender, except for "circuit-breaker", which uses an optional second
+// Sender as its fallback. The "multi" and "router" types send messages
+// to all of their Senders.
+func init() {
+	for name, factory := range map[string]SenderFactory{
+		"native":          buildNativeSender,
+		"console":         buildNativeSender,
+		"stderr":          buildErrorSender,
+		"file":            buildFileSender,
+		"json-console":    buildJSONConsoleSender,
+		"color-console":   buildColorConsoleSender,
+		"github-actions":  buildGitHubActionsSender,
+		"teamcity":        buildTeamCitySender,
+		"junit":           buildJUnitSender,
+		"json-file":       buildJSONFileSender,
+		"slack":           buildSlackSender,
+		"smtp":            buildSMTPSender,
+		"xmpp":            buildXMPPSender,
+		"buildlogger":     buildBuildloggerSender,
+		"multi":           buildMultiSender,
+		"router":          buildRouterSender,
+		"buffered":        buildBufferedSender,
+		"dedup":           buildDedupSender,
+		"sample":          buildSamplingSender,
+		"redact":          buildRedactingSender,
+		"enrich":          buildEnrichingSender,
+		"backtrace":       buildBacktraceSender,
+		"escalate":        buildEscalatingSender,
+		"circuit-breaker": buildCircuitBreakerSender,
+	} {
+		if err := RegisterSenderType(name, factory); err != nil {
+			panic(err)
+		}
+	}
+
+	for name, factory := range map[string]FormatterFactory{
+		"default":  func(_ *FormatterConfig) (MessageFormatter, error) { return MakeDefaultFormatter(), nil },
+		"plain":    func(_ *FormatterConfig) (MessageFormatter, error) { return MakePlainFormatter(), nil },
+		"json":     buildJSONFormatter,
+		"callsite": buildCallSiteFormatter,
+		"logfmt":   buildLogfmtFormatter,
+		"template": buildTemplateFormatter,
+		"color":    buildColorFormatter,
+
+		"github-actions": func(_ *FormatterConfig) (MessageFormatter, error) { return MakeGitHubActionsFormatter(), nil },
+		"teamcity":       func(_ *FormatterConfig) (MessageFormatter, error) { return MakeTeamCityFormatter(), nil },
+	} {
+		if err := RegisterFormatterType(name, factory); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func noSenders(conf *SenderConfig, senders []Sender) error {
+	if len(senders) != 0 {
+		return fmt.Errorf("%s senders do not wrap other senders", conf.Type)
+	}
+
+	return conf.DecodeOptions(&struct{}{})
+}
+
+func wrappedSender(conf *SenderConfig, senders []Sender) (Sender, error) {
+	if len(senders) != 1 {
+		return nil, fmt.Errorf("%s senders must wrap exactly one sender", conf.Type)
+	}
+
+	return senders[0], nil
+}
+
+func buildNativeSender(conf *SenderConfig, senders []Sender) (Sender, error) {
+	if err := noSenders(conf, senders); err != nil {
+		return nil, err
+	}
+
+	return MakeNative(), nil
+}
+
+func buildErrorSender(conf *SenderConfig, senders []Sender) (Sender, error) {
+	if err := noSenders(conf, senders); err != nil {
+		return nil, err
+	}
+
+	return MakeErrorLogger(), nil
+}
+
+func buildJSONConsoleSender(conf *SenderConfig, senders []Sender) (Sender, error) {
+	if err := noSenders(conf, senders); err != nil {
+		return nil, err
+	}
+
+	return MakeJSONConsoleLogger(), nil
+}
+
+func buildColorConsoleSender(conf *SenderConfig, senders []Sender) (Sender, error) {
+	if len(senders) != 0 {
+		return nil, fmt.Errorf("%s senders do not wrap other senders", conf.Type)
+	}
+
+	opts := colorOptions{}
+	if err := conf.DecodeOptions(&opts); err != nil {
+		return nil, err
+	}
+
+	s := MakeColorConsoleLogger()
+	if opts.Color != "" && opts.Color != "auto" {
+		fm, err := opts.formatter()
+		if err != nil {
+			return nil, err
+		}
+		if err = s.SetFormatter(fm); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+// colorOptions configure whether color senders and formatters use
+// colors: "always", "never", or "auto", the default, which uses colors
+// when standard output is a terminal.
+type colorOptions struct {
+	Color string `json:"color"`
+}
+
+func (o colorOptions) formatter() (MessageFormatter, error) {
+	switch o.Color {
+	case "", "auto":
+		return MakeColorFormatter(ColorEnabled(os.Stdout)), nil
+	case "always":
+		return MakeColorFormatter(true), nil
+	case "never":
+		return MakeColorFormatter(false), nil
+	default:
+		return nil, fmt.Errorf("color '%s' is not 'always', 'never', or 'auto'", o.Color)
+	}
+}
+
+func buildGitHubActionsSender(conf *SenderConfig, senders []Sender) (Sender, error) {
+	if err := noSenders(conf, senders); err != nil {
+		return nil, err
+	}
+
+	return MakeGitHubActionsLogger(), nil
+}
+
+func buildTeamCitySender(conf *SenderConfig, senders []Sender) (Sender, error) {
+	if err := noSenders(conf, senders); err != nil {
+		return nil, err
+	}
+
+	return MakeTeamCityLogger(), nil
+}
+
+func buildJUnitSender(conf *SenderConfig, senders []Sender) (Sender, error) {
+	if len(senders) != 0 {
+		return nil, fmt.Errorf("%s senders do not wrap other senders", conf.Type)
+	}
+
+	opts := struct {
+		Path         string         `json:"path"`
+		Suite        string         `json:"suite"`
+		TestField    string         `json:"test_field"`
+		ResultField  string         `json:"result_field"`
+		FailureLevel configPriority `json:"failure_level"`
+	}{}
+	if err := conf.DecodeOptions(&opts); err != nil {
+		return nil, err
+	}
+
+	return NewJUnitSender(conf.Name, LevelInfo{level.Trace, level.Trace}, JUnitOptions{
+		Path:         opts.Path,
+		Suite:        opts.Suite,
+		TestField:    opts.TestField,
+		ResultField:  opts.ResultField,
+		FailureLevel: level.Priority(opts.FailureLevel),
+	})
+}
+
+type fileSenderOptions struct {
+	Path string `json:"path"`
+}
+
+func (c *SenderConfig) decodeFileOptions(senders []Sender) (string, error) {
+	if len(senders) != 0 {
+		return "", fmt.Errorf("%s senders do not wrap other senders", c.Type)
+	}
+
+	opts := fileSenderOptions{}
+	if err := c.DecodeOptions(&opts); err != nil {
+		return "", err
+	}
+
+	if opts.Path == "" {
+		return "", errors.New("must specify a path")
+	}
+
+	return opts.Path, nil
+}
+
+func buildFileSender(conf *SenderConfig, senders []Sender) (Sender, error) {
+	path, err := conf.decodeFileOptions(senders)
+	if err != nil {
+		return nil, err
+	}
+
+	return MakeFileLogger(path)
+}
+
+func buildJSONFileSender(conf *SenderConfig, senders []Sender) (Sender, error) {
+	path, err := conf.decodeFileOptions(senders)
+	if err != nil {
+		return nil, err
+	}
+
+	return MakeJSONFileLogger(path)
+}
+
+func buildSlackSender(conf *SenderConfig, senders []Sender) (Sender, error) {
+	if len(senders) != 0 {
+		return nil, errors.New("slack senders do not wrap other senders")
+	}
+
+	opts := struct {
+		Token         string   `json:"token"`
+		Channel       string   `json:"channel"`
+		Hostname      string   `json:"hostname"`
+		BasicMetadata bool     `json:"basic_metadata"`
+		Fields        bool     `json:"fields"`
+		FieldsSet     []string `json:"fields_set"`
+	}{}
+	if err := conf.DecodeOptions(&opts); err != nil {
+		return nil, err
+	}
+
+	// the token defaults to the environment variable, to keep
+	// credentials out of configuration files.
+	if opts.Token == "" {
+		opts.Token = os.Getenv(slackClientToken)
+	}
+
+	if opts.Token == "" {
+		return nil, fmt.Errorf("must specify a token or define %s", slackClientToken)
+	}
+
+	slack := &SlackOptions{
+		Channel:       opts.Channel,
+		Hostname:      opts.Hostname,
+		Name:          conf.Name,
+		BasicMetadata: opts.BasicMetadata,
+		Fields:        opts.Fields,
+	}
+
+	if len(opts.FieldsSet) > 0 {
+		slack.FieldsSet = map[string]struct{}{}
+		for _, f := range opts.FieldsSet {
+			slack.FieldsSet[f] = struct{}{}
+		}
+	}
+
+	return NewSlackLogger(slack, opts.Token, LevelInfo{level.Trace, level.Trace})
+}
+
+func buildSMTPSender(conf *SenderConfig, senders []Sender) (Sender, error) {
+	if len(senders) != 0 {
+		return nil, errors.New("smtp senders do not wrap other senders")
+	}
+
+	opts := struct {
+		From                          string   `json:"from"`
+		Recipients                    []string `json:"recipients"`
+		Server                        string   `json:"server"`
+		Port                          int      `json:"port"`
+		UseSSL                        bool     `json:"use_ssl"`
+		Username                      string   `json:"username"`
+		Password                      string   `json:"password"`
+		Subject                       string   `json:"subject"`
+		TruncatedMessageSubjectLength int      `json:"truncated_message_subject_length"`
+		NameAsSubject                 bool     `json:"name_as_subject"`
+		MessageAsSubject              bool     `json:"message_as_subject"`
+	}{}
+	if err := conf.DecodeOptions(&opts); err != nil {
+		return nil, err
+	}
+
+	smtp := &SMTPOptions{
+		Name:                          conf.Name,
+		From:                          opts.From,
+		Server:                        opts.Server,
+		Port:                          opts.Port,
+		UseSSL:                        opts.UseSSL,
+		Username:                      opts.Username,
+		Password:                      opts.Password,
+		Subject:                       opts.Subject,
+		TruncatedMessageSubjectLength: opts.TruncatedMessageSubjectLength,
+		NameAsSubject:                 opts.NameAsSubject,
+		MessageAsSubject:              opts.MessageAsSubject,
+	}
+
+	if err := smtp.AddRecipients(opts.Recipients...); err != nil {
+		return nil, err
+	}
+
+	return NewSMTPLogger(smtp, LevelInfo{level.Trace, level.Trace})
+}
+
+func buildXMPPSender(conf *SenderConfig, senders []Sender) (Sender, error) {
+	if len(senders) != 0 {
+		return nil, errors.New("xmpp senders do not wrap other senders")
+	}
+
+	opts := struct {
+		Target   string `json:"target"`
+		Hostname string `json:"hostname"`
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}{}
+	if err := conf.DecodeOptions(&opts); err != nil {
+		return nil, err
+	}
+
+	if opts.Target == "" {
+		return nil, errors.New("must specify a target")
+	}
+
+	// connection settings that are not configured default to the
+	// environment variables that MakeXMPP reads.
+	info := GetXMPPConnectionInfo()
+	if opts.Hostname != "" {
+		info.Hostname = opts.Hostname
+	}
+	if opts.Username != "" {
+		info.Username = opts.Username
+	}
+	if opts.Password != "" {
+		info.Password = opts.Password
+	}
+
+	return NewXMPPLogger(conf.Name, opts.Target, info, LevelInfo{level.Trace, level.Trace})
+}
+
+func buildBuildloggerSender(conf *SenderConfig, senders []Sender) (Sender, error) {
+	if len(senders) > 1 {
+		return nil, errors.New("buildlogger senders have at most one local sender")
+	}
+
+	opts := struct {
+		URL            string         `json:"url"`
+		CreateTest     bool           `json:"create_test"`
+		Number         int            `json:"number"`
+		Phase          string         `json:"phase"`
+		Builder        string         `json:"builder"`
+		Test           string         `json:"test"`
+		Command        string         `json:"command"`
+		BufferCount    int            `json:"buffer_count"`
+		BufferInterval configDuration `json:"buffer_interval"`
+		Credentials    string         `json:"credentials"`
+	}{}
+	if err := conf.DecodeOptions(&opts); err != nil {
+		return nil, err
+	}
+
+	bconf := &BuildloggerConfig{
+		URL:            opts.URL,
+		CreateTest:     opts.CreateTest,
+		Number:         opts.Number,
+		Phase:          opts.Phase,
+		Builder:        opts.Builder,
+		Test:           opts.Test,
+		Command:        opts.Command,
+		BufferCount:    opts.BufferCount,
+		BufferInterval: time.Duration(opts.BufferInterval),
+	}
+
+	// use the same defaults as GetBuildloggerConfig
+	if bconf.BufferCount == 0 {
+		bconf.BufferCount = 1000
+	}
+	if bconf.BufferInterval == 0 {
+		bconf.BufferInterval = 20 * time.Second
+	}
+	if bconf.Test == "" {
+		bconf.Test = "unknown"
+	}
+	if bconf.Phase == "" {
+		bconf.Phase = "unknown"
+	}
+
+	if opts.Credentials != "" {
+		if err := bconf.ReadCredentialsFromFile(opts.Credentials); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(senders) == 1 {
+		bconf.Local = senders[0]
+	}
+
+	return MakeBuildlogger(conf.Name, bconf)
+}
+
+func buildMultiSender(conf *SenderConfig, senders []Sender) (Sender, error) {
+	if err := conf.DecodeOptions(&struct{}{}); err != nil {
+		return nil, err
+	}
+
+	if len(senders) == 0 {
+		return nil, errors.New("multi senders must have at least one sender")
+	}
+
+	return NewConfiguredMultiSender(senders...), nil
+}
+
+// buildRouterSender constructs a router without routes; BuildSender
+// constructs routers itself, because factories do not have access to
+// the Senders built for the routes.
+func buildRouterSender(conf *SenderConfig, senders []Sender) (Sender, error) {
+	if err := conf.DecodeOptions(&struct{}{}); err != nil {
+		return nil, err
+	}
+
+	return MakeRouterSender(senders)
+}
+
+func buildBufferedSender(conf *SenderConfig, senders []Sender) (Sender, error) {
+	sender, err := wrappedSender(conf, senders)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := struct {
+		Interval configDuration `json:"interval"`
+		Count    int            `json:"count"`
+	}{}
+	if err := conf.DecodeOptions(&opts); err != nil {
+		return nil, err
+	}
+
+	return NewBufferedSender(sender, time.Duration(opts.Interval), opts.Count), nil
+}
+
+func buildDedupSender(conf *SenderConfig, senders []Sender) (Sender, error) {
+	sender, err := wrappedSender(conf, senders)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := struct {
+		Window configDuration `json:"window"`
+		Fields []string       `json:"fields"`
+	}{}
+	if err := conf.DecodeOptions(&opts); err != nil {
+		return nil, err
+	}
+
+	return NewDedupSender(sender, time.Duration(opts.Window), opts.Fields...), nil
+}
+
+func buildSamplingSender(conf *SenderConfig, senders []Sender) (Sender, error) {
+	sender, err := wrappedSender(conf, senders)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := struct {
+		Rate      float64 `json:"rate"`
+		Key       string  `json:"key"`
+		First     int     `json:"first"`
+		Every     int     `json:"every"`
+		PerSecond int     `json:"per_second"`
+	}{}
+	if err := conf.DecodeOptions(&opts); err != nil {
+		return nil, err
+	}
+
+	samplers := []Sampler{}
+	switch {
+	case opts.Rate < 0 || opts.Rate > 1:
+		return nil, fmt.Errorf("sampling rate %f is not between 0 and 1", opts.Rate)
+	case opts.Key != "" && opts.Rate > 0:
+		samplers = append(samplers, SampleByKey(opts.Key, opts.Rate))
+	case opts.Key != "":
+		return nil, errors.New("must specify a rate to sample by key")
+	case opts.Rate > 0:
+		samplers = append(samplers, SampleRate(nil, opts.Rate))
+	}
+
+	if opts.Every > 0 {
+		samplers = append(samplers, SampleFirstThenEvery(opts.First, opts.Every))
+	}
+
+	if opts.PerSecond > 0 {
+		samplers = append(samplers, SampleLimit(opts.PerSecond))
+	}
+
+	if len(samplers) == 0 {
+		return nil, errors.New("must specify a rate, every, or per_second")
+	}
+
+	return NewSamplingSender(sender, samplers...), nil
+}
+
+func buildRedactingSender(conf *SenderConfig, senders []Sender) (Sender, error) {
+	sender, err := wrappedSender(conf, senders)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := struct {
+		MaskKeys        []string `json:"mask_keys"`
+		Patterns        []string `json:"patterns"`
+		DefaultPatterns bool     `json:"default_patterns"`
+		Mask            string   `json:"mask"`
+		HashKeys        []string `json:"hash_keys"`
+		HashPatterns    []string `json:"hash_patterns"`
+		HashSecret      string   `json:"hash_secret"`
+	}{}
+	if err := conf.DecodeOptions(&opts); err != nil {
+		return nil, err
+	}
+
+	ropts := &RedactionOptions{
+		MaskKeys:   opts.MaskKeys,
+		Mask:       opts.Mask,
+		HashKeys:   opts.HashKeys,
+		HashSecret: []byte(opts.HashSecret),
+	}
+
+	if opts.DefaultPatterns {
+		ropts.Patterns = DefaultRedactionPatterns()
+	}
+
+	if ropts.Patterns, err = compileConfigPatterns(ropts.Patterns, opts.Patterns); err != nil {
+		return nil, err
+	}
+
+	if ropts.HashPatterns, err = compileConfigPatterns(nil, opts.HashPatterns); err != nil {
+		return nil, err
+	}
+
+	return NewRedactingSender(sender, ropts)
+}
+
+func compileConfigPatterns(out []*regexp.Regexp, patterns []string) ([]*regexp.Regexp, error) {
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("pattern '%s' is not valid: %s", p, err.Error())
+		}
+		out = append(out, re)
+	}
+
+	return out, nil
+}
+
+func buildEnrichingSender(conf *SenderConfig, senders []Sender) (Sender, error) {
+	sender, err := wrappedSender(conf, senders)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := struct {
+		Fields     map[string]interface{} `json:"fields"`
+		Process    bool                   `json:"process"`
+		Build      bool                   `json:"build"`
+		Kubernetes bool                   `json:"kubernetes"`
+	}{}
+	if err := conf.DecodeOptions(&opts); err != nil {
+		return nil, err
+	}
+
+	return NewEnrichingSender(sender, EnrichmentOptions{
+		Fields:     message.Fields(opts.Fields),
+		Process:    opts.Process,
+		Build:      opts.Build,
+		Kubernetes: opts.Kubernetes,
+	}), nil
+}
+
+func buildBacktraceSender(conf *SenderConfig, senders []Sender) (Sender, error) {
+	sender, err := wrappedSender(conf, senders)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := struct {
+		Trigger  configPriority `json:"trigger"`
+		Capture  configPriority `json:"capture"`
+		Size     int            `json:"size"`
+		Duration configDuration `json:"duration"`
+		Key      string         `json:"key"`
+		MaxKeys  int            `json:"max_keys"`
+	}{}
+	if err := conf.DecodeOptions(&opts); err != nil {
+		return nil, err
+	}
+
+	return NewBacktraceSender(sender, BacktraceOptions{
+		Trigger:  level.Priority(opts.Trigger),
+		Capture:  level.Priority(opts.Capture),
+		Size:     opts.Size,
+		Duration: time.Duration(opts.Duration),
+		Key:      opts.Key,
+		MaxKeys:  opts.MaxKeys,
+	})
+}
+
+func buildEscalatingSender(conf *SenderConfig, senders []Sender) (Sender, error) {
+	sender, err := wrappedSender(conf, senders)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := struct {
+		Count    int            `json:"count"`
+		Window   configDuration `json:"window"`
+		Duration configDuration `json:"duration"`
+		Escalate configPriority `json:"escalate"`
+		Minimum  configPriority `json:"minimum"`
+		Fields   []string       `json:"fields"`
+	}{}
+	if err := conf.DecodeOptions(&opts); err != nil {
+		return nil, err
+	}
+
+	return NewEscalatingSender(sender, EscalationOptions{
+		Count:    opts.Count,
+		Window:   time.Duration(opts.Window),
+		Duration: time.Duration(opts.Duration),
+		Escalate: level.Priority(opts.Escalate),
+		Minimum:  level.Priority(opts.Minimum),
+		Fields:   opts.Fields,
+	})
+}
+
+func buildCircuitBreakerSender(conf *SenderConfig, senders []Sender) (Sender, error) {
+	if len(senders) == 0 || len(senders) > 2 {
+		return nil, errors.New("circuit-breaker senders must wrap one sender, and an optional fallback")
+	}
+
+	opts := struct {
+		Threshold int            `json:"threshold"`
+		CoolDown  configDuration `json:"cool_down"`
+		Timeout   configDuration `json:"timeout"`
+	}{}
+	if err := conf.DecodeOptions(&opts); err != nil {
+		return nil, err
+	}
+
+	bopts := CircuitBreakerOptions{
+		Threshold: opts.Threshold,
+		CoolDown:  time.Duration(opts.CoolDown),
+		Timeout:   time.Duration(opts.Timeout),
+	}
+
+	if len(senders) == 2 {
+		bopts.Fallback = senders[1]
+	}
+
+	return NewCircuitBreakerSender(senders[0], bopts)
+}
+
+func buildCallSiteFormatter(conf *FormatterConfig) (MessageFormatter, error) {
+	opts := struct {
+		Depth int `json:"depth"`
+	}{}
+	if err := conf.DecodeOptions(&opts); err != nil {
+		return nil, err
+	}
+
+	return MakeCallSiteFormatter(opts.Depth), nil
+}
+
+func buildLogfmtFormatter(conf *FormatterConfig) (MessageFormatter, error) {
+	opts := struct {
+		Name string `json:"name"`
+	}{}
+	if err := conf.DecodeOptions(&opts); err != nil {
+		return nil, err
+	}
+
+	return MakeLogfmtFormatter(opts.Name), nil
+}
+
+func buildTemplateFormatter(conf *FormatterConfig) (MessageFormatter, error) {
+	opts := struct {
+		Name     string `json:"name"`
+		Template string `json:"template"`
+	}{}
+	if err := conf.DecodeOptions(&opts); err != nil {
+		return nil, err
+	}
+
+	if opts.Template == "" {
+		return nil, errors.New("template formatters must specify a template")
+	}
+
+	return NewTemplateFormatter(opts.Name, opts.Template)
+}
+
+func buildColorFormatter(conf *FormatterConfig) (MessageFormatter, error) {
+	opts := colorOptions{}
+	if err := conf.DecodeOptions(&opts); err != nil {
+		return nil, err
+	}
+
+	return opts.formatter()
+}
+
+// buildJSONFormatter builds a formatter that renders the Raw form of
+// messages, unless the configuration has options, which configure a
+// formatter as in NewJSONFormatter.
+func buildJSONFormatter(conf *FormatterConfig) (MessageFormatter, error) {
+	if len(conf.Options) == 0 {
+		return MakeJSONFormatter(), nil
+	}
+
+	opts := struct {
+		Schema     string            `json:"schema"`
+		Name       string            `json:"name"`
+		TimeFormat string            `json:"time_format"`
+		FieldsKey  string            `json:"fields_key"`
+		Keys       map[string]string `json:"keys"`
+	}{}
+	if err := conf.DecodeOptions(&opts); err != nil {
+		return nil, err
+	}
+
+	return NewJSONFormatter(JSONFormatterOptions{
+		Schema:     JSONSchema(opts.Schema),
+		Name:       opts.Name,
+		TimeFormat: opts.TimeFormat,
+		FieldsKey:  opts.FieldsKey,
+		Keys:       opts.Keys,
+	})
+}
@@ -0,0 +1,88 @@
+package send
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/mongodb/grip/message"
+)
+
+// ContextSender is implemented by Senders that can deliver a message
+// synchronously and report the outcome. SendContext returns when the
+// message has been delivered, or when delivery failed or the context
+// was canceled. Unlike Send, SendContext returns errors rather than
+// passing them to the Sender's error handler.
+//
+// Messages that the Sender does not log because of its level
+// configuration are not errors: SendContext returns nil for them.
+//
+// Many Senders deliver messages with clients that do not accept a
+// context, and when the context is canceled, SendContext returns
+// without canceling the delivery, which continues in the background.
+// Canceling the context therefore bounds how long the caller waits,
+// but the message may still be delivered.
+type ContextSender interface {
+	Sender
+	SendContext(context.Context, message.Composer) error
+}
+
+// SendContext is a helper function that sends a message using the
+// Sender's SendContext method, if the Sender implements
+// ContextSender. Otherwise, SendContext returns the context's error
+// if the context is already done, and calls Send and returns nil
+// if it is not.
+func SendContext(ctx context.Context, s Sender, m message.Composer) error {
+	if cs, ok := s.(ContextSender); ok {
+		return cs.SendContext(ctx, m)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.Send(m)
+	return nil
+}
+
+// runContext calls the function, and returns its error, or the
+// context's error if the context is done before the function
+// returns. In the latter case the function continues to run in the
+// background, so Senders use this for operations that do not accept
+// a context themselves.
+func runContext(ctx context.Context, op func() error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if ctx.Done() == nil {
+		return op()
+	}
+
+	errs := make(chan error, 1)
+	go func() { errs <- op() }()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// sendContextAll sends the message to all of the Senders, and returns
+// an error that combines the errors from all of the Senders.
+func sendContextAll(ctx context.Context, senders []Sender, m message.Composer) error {
+	errs := []string{}
+	for _, s := range senders {
+		if err := SendContext(ctx, s, m); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "\n"))
+	}
+
+	return nil
+}
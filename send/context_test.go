@@ -0,0 +1,155 @@
+package send
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mongodb/grip/level"
+	"github.com/mongodb/grip/message"
+	"github.com/stretchr/testify/assert"
+)
+
+// failingWriter returns an error for every write.
+type failingWriter struct{}
+
+func (failingWriter) WriteString(string) (int, error) { return 0, errors.New("disk full") }
+
+func TestSendContextReturnsDeliveryErrors(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	l := LevelInfo{level.Info, level.Info}
+
+	buf := &bytes.Buffer{}
+	good, err := NewStreamLogger("good", buf, l)
+	assert.NoError(err)
+	assert.Implements((*ContextSender)(nil), good)
+
+	assert.NoError(SendContext(ctx, good, message.NewDefaultMessage(level.Info, "hello")))
+	assert.Equal("hello\n", buf.String())
+
+	// filtered messages are not errors
+	assert.NoError(SendContext(ctx, good, message.NewDefaultMessage(level.Debug, "quiet")))
+	assert.Equal("hello\n", buf.String())
+
+	bad, err := NewStreamLogger("bad", failingWriter{}, l)
+	assert.NoError(err)
+
+	handled := 0
+	assert.NoError(bad.SetErrorHandler(func(error, message.Composer) { handled++ }))
+
+	err = SendContext(ctx, bad, message.NewDefaultMessage(level.Info, "hello"))
+	assert.EqualError(err, "disk full")
+	assert.Equal(0, handled)
+
+	bad.Send(message.NewDefaultMessage(level.Info, "hello"))
+	assert.Equal(1, handled)
+}
+
+func TestSendContextHonorsCancellation(t *testing.T) {
+	assert := assert.New(t)
+
+	sink, err := NewInternalLogger("sink", LevelInfo{level.Info, level.Info})
+	assert.NoError(err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// the internal sender has room, but stream senders check the
+	// context before writing.
+	stream, err := NewStreamLogger("stream", &bytes.Buffer{}, LevelInfo{level.Info, level.Info})
+	assert.NoError(err)
+	assert.Equal(context.Canceled, SendContext(ctx, stream, message.NewDefaultMessage(level.Info, "hello")))
+
+	// senders that do not implement SendContext are not called
+	dedup := NewDedupSender(sink, time.Minute)
+	assert.Equal(context.Canceled, SendContext(ctx, dedup, message.NewDefaultMessage(level.Info, "hello")))
+	assert.Equal(0, sink.Len())
+	assert.NoError(SendContext(context.Background(), dedup, message.NewDefaultMessage(level.Info, "hello")))
+	assert.Equal(1, sink.Len())
+
+	// blocked senders return when the deadline passes
+	for sink.Len() < cap(sink.output) {
+		sink.Send(message.NewDefaultMessage(level.Info, "fill"))
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Equal(context.DeadlineExceeded, SendContext(ctx, sink, message.NewDefaultMessage(level.Info, "hello")))
+}
+
+func TestRunContextReturnsBeforeSlowOperations(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := runContext(ctx, func() error {
+		time.Sleep(time.Second)
+		return nil
+	})
+	assert.Equal(context.DeadlineExceeded, err)
+	assert.True(time.Since(start) < time.Second)
+
+	assert.EqualError(runContext(context.Background(), func() error { return errors.New("failed") }), "failed")
+}
+
+func TestMultiSendContextAggregatesErrors(t *testing.T) {
+	assert := assert.New(t)
+	l := LevelInfo{level.Info, level.Info}
+
+	buf := &bytes.Buffer{}
+	good, err := NewStreamLogger("good", buf, l)
+	assert.NoError(err)
+	one, err := NewStreamLogger("one", failingWriter{}, l)
+	assert.NoError(err)
+	two, err := NewStreamLogger("two", failingWriter{}, l)
+	assert.NoError(err)
+
+	multi := NewConfiguredMultiSender(one, good, two)
+	err = SendContext(context.Background(), multi, message.NewDefaultMessage(level.Info, "hello"))
+	assert.Error(err)
+	assert.Equal([]string{"disk full", "disk full"}, strings.Split(err.Error(), "\n"))
+	assert.Equal("hello\n", buf.String())
+
+	router, err := MakeRouterSender(nil, Route{MinPriority: level.Error, Senders: []Sender{one, good}})
+	assert.NoError(err)
+	assert.EqualError(SendContext(context.Background(), router, message.NewDefaultMessage(level.Error, "routed")), "disk full")
+	assert.NoError(SendContext(context.Background(), router, message.NewDefaultMessage(level.Info, "unrouted")))
+	assert.Equal("hello\nrouted\n", buf.String())
+}
+
+func TestCircuitBreakerSendContext(t *testing.T) {
+	assert := assert.New(t)
+	l := LevelInfo{level.Info, level.Info}
+
+	wrapped, err := NewStreamLogger("wrapped", failingWriter{}, l)
+	assert.NoError(err)
+	fallback, err := NewInternalLogger("fallback", l)
+	assert.NoError(err)
+
+	breaker, err := NewCircuitBreakerSender(wrapped, CircuitBreakerOptions{Threshold: 1, CoolDown: time.Hour})
+	assert.NoError(err)
+	assert.EqualError(SendContext(context.Background(), breaker, message.NewDefaultMessage(level.Info, "one")), "disk full")
+
+	state, err := GetCircuitState(breaker)
+	assert.NoError(err)
+	assert.Equal(CircuitOpen, state)
+	assert.Error(SendContext(context.Background(), breaker, message.NewDefaultMessage(level.Info, "two")))
+
+	breaker, err = NewCircuitBreakerSender(wrapped, CircuitBreakerOptions{Fallback: fallback, Threshold: 1, CoolDown: time.Hour})
+	assert.NoError(err)
+	assert.Error(SendContext(context.Background(), breaker, message.NewDefaultMessage(level.Info, "one")))
+	assert.NoError(SendContext(context.Background(), breaker, message.NewDefaultMessage(level.Info, "two")))
+
+	// the failed message, the state change, and the message sent
+	// while the circuit is open.
+	assert.Equal(3, fallback.Len())
+	assert.Equal("one", fallback.GetMessage().Rendered)
+	assert.Equal(level.Warning, fallback.GetMessage().Priority)
+	assert.Equal("two", fallback.GetMessage().Rendered)
+}
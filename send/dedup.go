@@ -0,0 +1,213 @@
+package send
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mongodb/grip/message"
+)
+
+const defaultDedupWindow = time.Minute
+
+type dedupSender struct {
+	senderWrapper
+
+	window time.Duration
+	fields []string
+	last   *dedupRecord
+	timer  *time.Timer
+	mutex  sync.Mutex
+}
+
+type dedupRecord struct {
+	fingerprint string
+	msg         message.Composer
+	count       int
+	first       time.Time
+	last        time.Time
+}
+
+// NewDedupSender wraps an existing Sender and suppresses consecutive
+// duplicate messages. Messages are considered duplicates when they
+// have the same priority, the same string form, and the same values
+// for each of the specified fields (when the message's Raw form is a
+// message.Fields value). Timestamps are never part of the comparison.
+//
+// Repeats are suppressed as long as each one arrives within the
+// window of the previous occurrence. When the window closes, or when
+// a different message arrives, the sender emits a single message
+// reporting the number of suppressed repeats and the times of the
+// first and last repeat.
+//
+// The members of a message.GroupComposer are deduplicated
+// individually. If the window is 0, the constructor uses a window of
+// one minute.
+func NewDedupSender(sender Sender, window time.Duration, fields ...string) Sender {
+	if window <= 0 {
+		window = defaultDedupWindow
+	}
+
+	return &dedupSender{
+		senderWrapper: newSenderWrapper(sender),
+		window:        window,
+		fields:        fields,
+	}
+}
+
+func (s *dedupSender) Send(m message.Composer) {
+	switch msg := m.(type) {
+	case *message.GroupComposer:
+		for _, member := range msg.Messages() {
+			s.send(member)
+		}
+	default:
+		s.send(msg)
+	}
+}
+
+func (s *dedupSender) send(m message.Composer) {
+	if !s.Level().ShouldLog(m) {
+		s.stats.recordFiltered()
+		return
+	}
+
+	fp := messageFingerprint(m, s.fields)
+	now := time.Now()
+
+	s.mutex.Lock()
+	if s.last != nil && s.last.fingerprint == fp && now.Sub(s.last.last) < s.window {
+		if s.last.count == 0 {
+			s.last.first = now
+		}
+		s.last.count++
+		s.last.last = now
+		s.timer.Reset(s.window)
+		s.mutex.Unlock()
+		s.stats.recordDropped()
+		return
+	}
+
+	summary := s.takeSummary()
+	s.last = &dedupRecord{
+		fingerprint: fp,
+		msg:         m,
+		last:        now,
+	}
+	s.startTimer(s.last)
+	s.mutex.Unlock()
+
+	// send outside of the lock, so that slow Senders do not block
+	// other goroutines, or the timer, while they deliver messages.
+	s.sendSummary(summary)
+
+	start := time.Now()
+	s.Sender.Send(m)
+	s.stats.recordSent(m.Priority(), time.Since(start))
+}
+
+func (s *dedupSender) startTimer(record *dedupRecord) {
+	s.timer = time.AfterFunc(s.window, func() {
+		s.mutex.Lock()
+		// a newer message has replaced this record since the
+		// timer started; it will have its own timer.
+		if s.last != record {
+			s.mutex.Unlock()
+			return
+		}
+
+		summary := s.takeSummary()
+		s.last = nil
+		s.mutex.Unlock()
+
+		s.sendSummary(summary)
+	})
+}
+
+// takeSummary returns the summary for the current record, if it has
+// suppressed repeats, and stops its timer. Callers must hold the lock,
+// and send the summary with sendSummary after releasing it.
+func (s *dedupSender) takeSummary() message.Composer {
+	if s.last == nil {
+		return nil
+	}
+
+	s.timer.Stop()
+
+	if s.last.count == 0 {
+		return nil
+	}
+
+	summary := message.NewFieldsMessage(s.last.msg.Priority(),
+		fmt.Sprintf("last message repeated %d times", s.last.count),
+		message.Fields{
+			"repeated": s.last.count,
+			"first":    s.last.first,
+			"last":     s.last.last,
+			"original": s.last.msg.String(),
+		})
+	s.last.count = 0
+
+	return summary
+}
+
+func (s *dedupSender) sendSummary(summary message.Composer) {
+	if summary != nil {
+		s.Sender.Send(summary)
+	}
+}
+
+// Flush sends the summary for the suppressed repeats of the most
+// recent message, if any, and then flushes the wrapped Sender.
+func (s *dedupSender) Flush(ctx context.Context) error {
+	s.mutex.Lock()
+	summary := s.takeSummary()
+	s.last = nil
+	s.mutex.Unlock()
+
+	s.sendSummary(summary)
+
+	return Flush(ctx, s.Sender)
+}
+
+func (s *dedupSender) Close() error {
+	s.mutex.Lock()
+	summary := s.takeSummary()
+	s.last = nil
+	s.mutex.Unlock()
+
+	s.sendSummary(summary)
+
+	return s.Sender.Close()
+}
+
+// messageFingerprint produces a string that identifies the content of
+// a message, for comparing messages without regard for when they were
+// logged. If fields are specified and the message's raw form is a
+// message.Fields value, the values of those fields are included.
+func messageFingerprint(m message.Composer, fields []string) string {
+	parts := []string{m.Priority().String(), m.String()}
+
+	if len(fields) > 0 {
+		if raw, ok := m.Raw().(message.Fields); ok {
+			keys := make([]string, len(fields))
+			copy(keys, fields)
+			sort.Strings(keys)
+
+			for _, k := range keys {
+				if k == "time" {
+					continue
+				}
+
+				if v, ok := raw[k]; ok {
+					parts = append(parts, fmt.Sprintf("%s=%v", k, v))
+				}
+			}
+		}
+	}
+
+	return strings.Join(parts, "\x00")
+}
@@ -0,0 +1,118 @@
+package send
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mongodb/grip/level"
+	"github.com/mongodb/grip/message"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDedupSenderSuppressesRepeats(t *testing.T) {
+	assert := assert.New(t)
+
+	sink, err := NewInternalLogger("dedup", LevelInfo{level.Debug, level.Debug})
+	assert.NoError(err)
+	s := NewDedupSender(sink, time.Hour)
+
+	for i := 0; i < 10; i++ {
+		s.Send(message.NewDefaultMessage(level.Error, "broken"))
+	}
+	assert.Equal(1, sink.Len())
+	assert.Equal("broken", sink.GetMessage().Rendered)
+
+	s.Send(message.NewDefaultMessage(level.Error, "different"))
+	assert.Equal(2, sink.Len())
+
+	summary := sink.GetMessage()
+	assert.Equal(level.Error, summary.Priority)
+	fields := summary.Message.Raw().(message.Fields)
+	assert.Equal(9, fields["repeated"])
+	assert.Equal("broken", fields["original"])
+	assert.Equal("different", sink.GetMessage().Rendered)
+
+	// a different priority is a different message
+	s.Send(message.NewDefaultMessage(level.Warning, "different"))
+	assert.Equal(1, sink.Len())
+	assert.Equal(level.Warning, sink.GetMessage().Priority)
+
+	// close emits the pending summary
+	s.Send(message.NewDefaultMessage(level.Warning, "different"))
+	assert.Equal(0, sink.Len())
+	assert.NoError(s.Close())
+	assert.Equal(1, sink.Len())
+	assert.Equal(1, sink.GetMessage().Message.Raw().(message.Fields)["repeated"])
+}
+
+func TestDedupSenderWindowExpires(t *testing.T) {
+	assert := assert.New(t)
+
+	sink, err := NewInternalLogger("dedup", LevelInfo{level.Debug, level.Debug})
+	assert.NoError(err)
+	s := NewDedupSender(sink, 20*time.Millisecond)
+
+	s.Send(message.NewDefaultMessage(level.Error, "broken"))
+	s.Send(message.NewDefaultMessage(level.Error, "broken"))
+	s.Send(message.NewDefaultMessage(level.Error, "broken"))
+	assert.Equal(1, sink.Len())
+
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(2, sink.Len())
+	sink.GetMessage()
+	assert.Equal(2, sink.GetMessage().Message.Raw().(message.Fields)["repeated"])
+
+	// after the window closes, the same message is delivered again
+	s.Send(message.NewDefaultMessage(level.Error, "broken"))
+	assert.Equal(1, sink.Len())
+}
+
+func TestDedupSenderFieldsAndGroups(t *testing.T) {
+	assert := assert.New(t)
+
+	sink, err := NewInternalLogger("dedup", LevelInfo{level.Debug, level.Debug})
+	assert.NoError(err)
+	s := NewDedupSender(sink, time.Hour, "host")
+
+	s.Send(message.MakeGroupComposer(
+		message.NewFieldsMessage(level.Info, "hi", message.Fields{"host": "a"}),
+		message.NewFieldsMessage(level.Info, "hi", message.Fields{"host": "a"}),
+		message.NewFieldsMessage(level.Info, "hi", message.Fields{"host": "b"}),
+	))
+
+	assert.Equal(3, sink.Len())
+	assert.Equal("[msg='hi' host='a']", sink.GetMessage().Rendered)
+	assert.Equal(1, sink.GetMessage().Message.Raw().(message.Fields)["repeated"])
+	assert.Equal("[msg='hi' host='b']", sink.GetMessage().Rendered)
+
+	// messages below the threshold are not tracked
+	assert.NoError(s.SetLevel(LevelInfo{level.Info, level.Info}))
+	s.Send(message.NewDefaultMessage(level.Debug, "quiet"))
+	assert.Equal(0, sink.Len())
+}
+
+func TestDedupSenderDoesNotHoldLockWhileSending(t *testing.T) {
+	assert := assert.New(t)
+
+	slow := newFlakySender()
+	slow.delay = 500 * time.Millisecond
+	s := NewDedupSender(slow, time.Hour)
+
+	sent := make(chan struct{})
+	go func() {
+		s.Send(message.NewDefaultMessage(level.Error, "slow"))
+		close(sent)
+	}()
+
+	// wait for the first message to reach the wrapped sender
+	time.Sleep(50 * time.Millisecond)
+
+	start := time.Now()
+	s.Send(message.NewDefaultMessage(level.Error, "slow"))
+	assert.True(time.Since(start) < 250*time.Millisecond)
+
+	<-sent
+	assert.Equal(int64(1), s.(StatsReporter).Stats().Dropped)
+	assert.NoError(s.Close())
+	assert.Equal(2, slow.sent)
+}
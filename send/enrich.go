@@ -0,0 +1,210 @@
+package send
+
+import (
+	"context"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"time"
+
+	"github.com/mongodb/grip/level"
+	"github.com/mongodb/grip/message"
+)
+
+// EnrichmentOptions configures the metadata that the enriching Sender
+// adds to every message.
+type EnrichmentOptions struct {
+	// Fields holds static metadata, e.g. the service name,
+	// version, environment, and region.
+	Fields message.Fields
+
+	// Dynamic maps field names to functions that the Sender
+	// calls for every message.
+	Dynamic map[string]func() interface{}
+
+	// Process adds the process id ("pid") and the number of
+	// running goroutines ("goroutines").
+	Process bool
+
+	// Build adds the VCS revision ("vcs_revision") that the
+	// binary was built from, when the Go toolchain recorded it.
+	Build bool
+
+	// Kubernetes adds the pod name ("k8s_pod") and namespace
+	// ("k8s_namespace") from the POD_NAME and POD_NAMESPACE
+	// environment variables, typically set with the downward
+	// API. The pod name falls back to HOSTNAME when running in a
+	// cluster.
+	Kubernetes bool
+}
+
+func (o EnrichmentOptions) static() message.Fields {
+	out := message.Fields{}
+
+	if o.Process {
+		out["pid"] = os.Getpid()
+	}
+
+	if o.Build {
+		if info, ok := debug.ReadBuildInfo(); ok {
+			for _, setting := range info.Settings {
+				if setting.Key == "vcs.revision" {
+					out["vcs_revision"] = setting.Value
+				}
+			}
+		}
+	}
+
+	if o.Kubernetes {
+		pod := os.Getenv("POD_NAME")
+		if pod == "" && os.Getenv("KUBERNETES_SERVICE_HOST") != "" {
+			pod = os.Getenv("HOSTNAME")
+		}
+		if pod != "" {
+			out["k8s_pod"] = pod
+		}
+
+		if ns := os.Getenv("POD_NAMESPACE"); ns != "" {
+			out["k8s_namespace"] = ns
+		}
+	}
+
+	for k, v := range o.Fields {
+		out[k] = v
+	}
+
+	return out
+}
+
+type enrichingSender struct {
+	senderWrapper
+	opts   EnrichmentOptions
+	static message.Fields
+}
+
+// NewEnrichingSender wraps an existing Sender and adds metadata, as
+// configured by the EnrichmentOptions, to every message. Static
+// metadata is collected when the Sender is constructed, and dynamic
+// metadata (including the goroutine count) when each message is sent.
+//
+// The Sender wraps each message in a new Composer whose Raw form is a
+// message.Fields value containing the metadata: for messages whose Raw
+// form is already a message.Fields value, the metadata is added to a
+// copy of those fields, and fields in the message take precedence
+// over metadata with the same name. Other messages are converted to
+// fields with the string form and send time of the message in the
+// "msg" and "time" fields. The string form of
+// messages is not changed, so senders that use the Raw form (e.g. the
+// JSON, Slack, and systemd senders) see the metadata, while
+// line-oriented senders produce the same output as before. The
+// members of a message.GroupComposer are enriched individually.
+func NewEnrichingSender(sender Sender, opts EnrichmentOptions) Sender {
+	return &enrichingSender{
+		senderWrapper: newSenderWrapper(sender),
+		opts:          opts,
+		static:        opts.static(),
+	}
+}
+
+func (s *enrichingSender) Send(m message.Composer) {
+	if !s.Level().ShouldLog(m) {
+		s.stats.recordFiltered()
+		return
+	}
+
+	start := time.Now()
+	s.Sender.Send(s.enrich(m))
+	s.stats.recordSent(m.Priority(), time.Since(start))
+}
+
+func (s *enrichingSender) SendContext(ctx context.Context, m message.Composer) error {
+	if !s.Level().ShouldLog(m) {
+		s.stats.recordFiltered()
+		return nil
+	}
+
+	start := time.Now()
+	if err := SendContext(ctx, s.Sender, s.enrich(m)); err != nil {
+		return err
+	}
+
+	s.stats.recordSent(m.Priority(), time.Since(start))
+	return nil
+}
+
+func (s *enrichingSender) metadata() message.Fields {
+	out := make(message.Fields, len(s.static)+len(s.opts.Dynamic)+1)
+	for k, v := range s.static {
+		out[k] = v
+	}
+
+	if s.opts.Process {
+		out["goroutines"] = runtime.NumGoroutine()
+	}
+
+	for k, fn := range s.opts.Dynamic {
+		out[k] = fn()
+	}
+
+	return out
+}
+
+func (s *enrichingSender) enrich(m message.Composer) message.Composer {
+	if group, ok := m.(*message.GroupComposer); ok {
+		msgs := group.Messages()
+		out := make([]message.Composer, len(msgs))
+		for idx := range msgs {
+			out[idx] = s.enrich(msgs[idx])
+		}
+
+		return message.NewGroupComposer(out)
+	}
+
+	return &enrichedMessage{original: m, metadata: s.metadata(), sent: time.Now()}
+}
+
+type enrichedMessage struct {
+	original message.Composer
+	metadata message.Fields
+	sent     time.Time
+	raw      message.Fields
+}
+
+func (m *enrichedMessage) String() string                     { return m.original.String() }
+func (m *enrichedMessage) Loggable() bool                     { return m.original.Loggable() }
+func (m *enrichedMessage) Priority() level.Priority           { return m.original.Priority() }
+func (m *enrichedMessage) SetPriority(p level.Priority) error { return m.original.SetPriority(p) }
+
+func (m *enrichedMessage) SetCallSite(frame message.StackFrame) {
+	if c, ok := m.original.(message.CallSiteComposer); ok {
+		c.SetCallSite(frame)
+	}
+}
+
+func (m *enrichedMessage) GetCallSite() (message.StackFrame, bool) {
+	return message.GetCallSite(m.original)
+}
+
+func (m *enrichedMessage) Raw() interface{} {
+	if m.raw != nil {
+		return m.raw
+	}
+
+	out := message.Fields{}
+	for k, v := range m.metadata {
+		out[k] = v
+	}
+
+	if fields, ok := m.original.Raw().(message.Fields); ok {
+		for k, v := range fields {
+			out[k] = v
+		}
+	} else {
+		out["msg"] = m.original.String()
+		out["time"] = m.sent
+	}
+
+	m.raw = out
+
+	return m.raw
+}
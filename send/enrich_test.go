@@ -0,0 +1,105 @@
+package send
+
+import (
+	"os"
+	"testing"
+
+	"github.com/mongodb/grip/level"
+	"github.com/mongodb/grip/message"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnrichingSender(t *testing.T) {
+	assert := assert.New(t)
+
+	defer os.Setenv("POD_NAMESPACE", os.Getenv("POD_NAMESPACE"))
+	defer os.Setenv("POD_NAME", os.Getenv("POD_NAME"))
+	assert.NoError(os.Setenv("POD_NAMESPACE", "prod"))
+	assert.NoError(os.Setenv("POD_NAME", "app-1234"))
+
+	sink, err := NewInternalLogger("enrich", LevelInfo{level.Info, level.Info})
+	require.NoError(t, err)
+
+	calls := 0
+	s := NewEnrichingSender(sink, EnrichmentOptions{
+		Fields:     message.Fields{"service": "api", "version": "1.2.3", "user": "default"},
+		Dynamic:    map[string]func() interface{}{"calls": func() interface{} { calls++; return calls }},
+		Process:    true,
+		Kubernetes: true,
+	})
+
+	s.Send(message.NewDefaultMessage(level.Debug, "dropped"))
+	s.Send(message.NewDefaultMessage(level.Info, "plain"))
+	s.Send(message.NewFieldsMessage(level.Info, "structured", message.Fields{"user": "alice"}))
+	require.Equal(t, 2, sink.Len())
+	assert.Equal(2, calls)
+
+	plain := sink.GetMessage()
+	assert.Equal("plain", plain.Rendered)
+	fields := plain.Message.Raw().(message.Fields)
+	assert.Equal("plain", fields["msg"])
+	assert.NotContains(fields, "priority")
+	assert.Equal("api", fields["service"])
+	assert.Equal(os.Getpid(), fields["pid"])
+	assert.Contains(fields, "goroutines")
+	assert.Contains(fields, "time")
+	assert.Equal("prod", fields["k8s_namespace"])
+	assert.Equal("app-1234", fields["k8s_pod"])
+	assert.Equal(1, fields["calls"])
+
+	structured := sink.GetMessage()
+	assert.Equal("[msg='structured' user='alice']", structured.Rendered)
+	fields = structured.Message.Raw().(message.Fields)
+	assert.Equal("alice", fields["user"])
+	assert.Equal("1.2.3", fields["version"])
+	assert.Equal(2, fields["calls"])
+
+	out, err := MakeJSONFormatter()(structured.Message)
+	assert.NoError(err)
+	assert.Contains(out, `"service":"api"`)
+}
+
+func TestEnrichingSenderGroups(t *testing.T) {
+	assert := assert.New(t)
+
+	sink, err := NewInternalLogger("enrich", LevelInfo{level.Info, level.Info})
+	require.NoError(t, err)
+
+	s := NewEnrichingSender(sink, EnrichmentOptions{Fields: message.Fields{"env": "test"}})
+	s.Send(message.MakeGroupComposer(
+		message.NewDefaultMessage(level.Info, "one"),
+		message.NewDefaultMessage(level.Info, "two")))
+	require.Equal(t, 1, sink.Len())
+
+	group, ok := sink.GetMessage().Message.(*message.GroupComposer)
+	require.True(t, ok)
+	for _, m := range group.Messages() {
+		assert.Equal("test", m.Raw().(message.Fields)["env"])
+	}
+}
+
+func TestEnrichingSenderKeepsCallSite(t *testing.T) {
+	assert := assert.New(t)
+
+	sink, err := NewInternalLogger("enrich", LevelInfo{level.Info, level.Info})
+	require.NoError(t, err)
+	s := NewEnrichingSender(sink, EnrichmentOptions{Fields: message.Fields{"service": "api"}})
+
+	frame := message.StackFrame{Function: "main.main", File: "main.go", Line: 7}
+	m := message.NewDefaultMessage(level.Info, "plain")
+	m.(message.CallSiteComposer).SetCallSite(frame)
+	s.Send(m)
+
+	require.Equal(t, 1, sink.Len())
+	out := sink.GetMessage().Message
+	site, ok := message.GetCallSite(out)
+	assert.True(ok)
+	assert.Equal(frame, site)
+
+	fm, err := NewJSONFormatter(JSONFormatterOptions{Schema: JSONSchemaGCP})
+	require.NoError(t, err)
+	rendered, err := fm(out)
+	assert.NoError(err)
+	assert.Contains(rendered, `"logging.googleapis.com/sourceLocation"`)
+}
@@ -0,0 +1,246 @@
+package send
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mongodb/grip/level"
+	"github.com/mongodb/grip/message"
+)
+
+// EscalationOptions configures the escalating Sender.
+type EscalationOptions struct {
+	// Count and Window define the frequency threshold: a message
+	// is escalated when it has occurred Count times within the
+	// Window. The Window also defines when a condition has
+	// stopped: if a message does not recur within the Window, its
+	// history is discarded. Window defaults to one minute.
+	Count  int
+	Window time.Duration
+
+	// Duration defines the persistence threshold: a message is
+	// escalated when it has recurred, with no gaps longer than
+	// the Window, for at least the Duration.
+	Duration time.Duration
+
+	// Escalate is the priority of escalated messages, and
+	// defaults to level.Alert.
+	Escalate level.Priority
+
+	// Minimum is the lowest priority of messages to track, and
+	// defaults to level.Warning.
+	Minimum level.Priority
+
+	// Fields are included in the fingerprint used to identify
+	// repeated messages, as with the dedup Sender.
+	Fields []string
+}
+
+// Validate checks the options and sets the default values.
+func (o *EscalationOptions) Validate() error {
+	if o == nil {
+		return errors.New("escalation options cannot be nil")
+	}
+
+	errs := []string{}
+
+	if o.Count <= 0 && o.Duration <= 0 {
+		errs = append(errs, "must specify a count or duration threshold")
+	}
+
+	if o.Count < 0 || o.Window < 0 || o.Duration < 0 {
+		errs = append(errs, "count, window, and duration must not be negative")
+	}
+
+	if o.Window == 0 {
+		o.Window = time.Minute
+	}
+
+	if o.Escalate == level.Invalid {
+		o.Escalate = level.Alert
+	} else if !level.IsValidPriority(o.Escalate) {
+		errs = append(errs, fmt.Sprintf("escalation priority %d is not valid", o.Escalate))
+	}
+
+	if o.Minimum == level.Invalid {
+		o.Minimum = level.Warning
+	} else if !level.IsValidPriority(o.Minimum) {
+		errs = append(errs, fmt.Sprintf("minimum priority %d is not valid", o.Minimum))
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+type escalationRecord struct {
+	first  time.Time
+	last   time.Time
+	count  int
+	recent []time.Time
+}
+
+type escalatingSender struct {
+	senderWrapper
+	opts    EscalationOptions
+	records map[string]*escalationRecord
+	pruned  time.Time
+	mutex   sync.Mutex
+}
+
+// NewEscalatingSender wraps an existing Sender and raises the
+// priority of messages that recur frequently or persistently, as
+// configured by the EscalationOptions, so that, for example, a
+// warning that occurs 50 times in a minute can page someone. Messages
+// are identified as with the dedup Sender.
+//
+// Escalated messages are sent as fields messages that include the
+// fields of the original message, with the original message and
+// priority, the number of occurrences, and the time since the first
+// occurrence in the "msg", "original_priority", "occurrences", and
+// "window" fields. Other messages are sent unchanged. Because
+// escalation happens before the wrapped Sender filters messages,
+// messages below the wrapped Sender's threshold are still tracked,
+// and are sent once they're escalated. The members of a
+// message.GroupComposer are handled individually.
+func NewEscalatingSender(sender Sender, opts EscalationOptions) (Sender, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &escalatingSender{
+		senderWrapper: newSenderWrapper(sender),
+		opts:          opts,
+		records:       map[string]*escalationRecord{},
+		pruned:        time.Now(),
+	}, nil
+}
+
+func (s *escalatingSender) Send(m message.Composer) {
+	if group, ok := m.(*message.GroupComposer); ok {
+		for _, member := range group.Messages() {
+			s.send(member)
+		}
+		return
+	}
+
+	s.send(m)
+}
+
+func (s *escalatingSender) send(m message.Composer) {
+	start := time.Now()
+	m = s.escalate(m)
+	s.Sender.Send(m)
+	s.stats.recordSent(m.Priority(), time.Since(start))
+}
+
+// SendContext escalates the message, as Send does, and sends it to
+// the wrapped Sender. For a message.GroupComposer, SendContext returns
+// an error that combines the errors for all of its members.
+func (s *escalatingSender) SendContext(ctx context.Context, m message.Composer) error {
+	msgs := []message.Composer{m}
+	if group, ok := m.(*message.GroupComposer); ok {
+		msgs = group.Messages()
+	}
+
+	errs := []string{}
+	for _, msg := range msgs {
+		start := time.Now()
+		msg = s.escalate(msg)
+		if err := SendContext(ctx, s.Sender, msg); err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		s.stats.recordSent(msg.Priority(), time.Since(start))
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "\n"))
+	}
+
+	return nil
+}
+
+func (s *escalatingSender) escalate(m message.Composer) message.Composer {
+	if !m.Loggable() || m.Priority() < s.opts.Minimum || m.Priority() >= s.opts.Escalate {
+		return m
+	}
+
+	fp := messageFingerprint(m, s.opts.Fields)
+	now := time.Now()
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.prune(now)
+
+	record, ok := s.records[fp]
+	if !ok || now.Sub(record.last) > s.opts.Window {
+		record = &escalationRecord{first: now}
+		s.records[fp] = record
+	}
+
+	record.last = now
+	record.count++
+
+	exceeded := false
+
+	if s.opts.Count > 0 {
+		record.recent = append(record.recent, now)
+		if len(record.recent) > s.opts.Count {
+			record.recent = record.recent[len(record.recent)-s.opts.Count:]
+		}
+
+		exceeded = len(record.recent) == s.opts.Count && now.Sub(record.recent[0]) <= s.opts.Window
+	}
+
+	if s.opts.Duration > 0 && now.Sub(record.first) >= s.opts.Duration {
+		exceeded = true
+	}
+
+	if !exceeded {
+		return m
+	}
+
+	msg := m.String()
+	fields := message.Fields{}
+	if raw, ok := m.Raw().(message.Fields); ok {
+		for k, v := range raw {
+			fields[k] = v
+		}
+		msg, _ = raw["msg"].(string)
+	}
+
+	fields["original_priority"] = m.Priority().String()
+	fields["occurrences"] = record.count
+	fields["window"] = now.Sub(record.first).String()
+
+	out := message.NewFieldsMessage(s.opts.Escalate, msg, fields)
+	if frame, ok := message.GetCallSite(m); ok {
+		out.(message.CallSiteComposer).SetCallSite(frame)
+	}
+
+	return out
+}
+
+// prune discards the records of conditions that have stopped, at most
+// once per window. Callers must hold the lock.
+func (s *escalatingSender) prune(now time.Time) {
+	if now.Sub(s.pruned) < s.opts.Window {
+		return
+	}
+
+	for fp, record := range s.records {
+		if now.Sub(record.last) > s.opts.Window {
+			delete(s.records, fp)
+		}
+	}
+
+	s.pruned = now
+}
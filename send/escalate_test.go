@@ -0,0 +1,110 @@
+package send
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mongodb/grip/level"
+	"github.com/mongodb/grip/message"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEscalationOptionsValidation(t *testing.T) {
+	assert := assert.New(t)
+
+	var opts *EscalationOptions
+	assert.Error(opts.Validate())
+	assert.Error((&EscalationOptions{}).Validate())
+	assert.Error((&EscalationOptions{Count: 1, Escalate: 1000}).Validate())
+	assert.Error((&EscalationOptions{Count: 1, Window: -1}).Validate())
+
+	opts = &EscalationOptions{Count: 10}
+	assert.NoError(opts.Validate())
+	assert.Equal(time.Minute, opts.Window)
+	assert.Equal(level.Alert, opts.Escalate)
+	assert.Equal(level.Warning, opts.Minimum)
+}
+
+func TestEscalatingSenderByFrequency(t *testing.T) {
+	assert := assert.New(t)
+
+	sink, err := NewInternalLogger("escalate", LevelInfo{level.Info, level.Error})
+	require.NoError(t, err)
+	s, err := NewEscalatingSender(sink, EscalationOptions{Count: 3})
+	require.NoError(t, err)
+
+	for i := 0; i < 4; i++ {
+		s.Send(message.NewDefaultMessage(level.Warning, "disk almost full"))
+	}
+	s.Send(message.NewDefaultMessage(level.Info, "not tracked"))
+	s.Send(message.NewDefaultMessage(level.Warning, "something else"))
+
+	require.Equal(t, 6, sink.Len())
+	for i := 0; i < 2; i++ {
+		out := sink.GetMessage()
+		assert.Equal(level.Warning, out.Priority)
+		assert.False(out.Logged)
+	}
+
+	for i := 3; i <= 4; i++ {
+		out := sink.GetMessage()
+		assert.Equal(level.Alert, out.Priority)
+		assert.True(out.Logged)
+		fields := out.Message.Raw().(message.Fields)
+		assert.Equal("disk almost full", fields["msg"])
+		assert.Equal("warning", fields["original_priority"])
+		assert.Equal(i, fields["occurrences"])
+	}
+
+	assert.Equal(level.Info, sink.GetMessage().Priority)
+	assert.Equal(level.Warning, sink.GetMessage().Priority)
+}
+
+func TestEscalatingSenderByDuration(t *testing.T) {
+	assert := assert.New(t)
+
+	sink, err := NewInternalLogger("escalate", LevelInfo{level.Info, level.Info})
+	require.NoError(t, err)
+	s, err := NewEscalatingSender(sink, EscalationOptions{
+		Duration: 30 * time.Millisecond,
+		Window:   time.Second,
+		Escalate: level.Critical,
+	})
+	require.NoError(t, err)
+
+	msg := func() message.Composer {
+		return message.NewFieldsMessage(level.Error, "replica lagging", message.Fields{"host": "db1"})
+	}
+
+	s.Send(msg())
+	assert.Equal(level.Error, sink.GetMessage().Priority)
+	time.Sleep(50 * time.Millisecond)
+	s.Send(message.MakeGroupComposer(msg()))
+
+	out := sink.GetMessage()
+	assert.Equal(level.Critical, out.Priority)
+	assert.Equal("[msg='replica lagging' host='db1' occurrences='2' original_priority='error' window='"+
+		out.Message.Raw().(message.Fields)["window"].(string)+"']", out.Rendered)
+}
+
+func TestEscalatingSenderKeepsCallSite(t *testing.T) {
+	assert := assert.New(t)
+
+	sink, err := NewInternalLogger("escalate", LevelInfo{level.Info, level.Info})
+	require.NoError(t, err)
+	s, err := NewEscalatingSender(sink, EscalationOptions{Count: 1})
+	require.NoError(t, err)
+
+	frame := message.StackFrame{Function: "main.main", File: "main.go", Line: 7}
+	m := message.NewDefaultMessage(level.Warning, "disk almost full")
+	m.(message.CallSiteComposer).SetCallSite(frame)
+	s.Send(m)
+
+	require.Equal(t, 1, sink.Len())
+	out := sink.GetMessage()
+	assert.Equal(level.Alert, out.Priority)
+	site, ok := message.GetCallSite(out.Message)
+	assert.True(ok)
+	assert.Equal(frame, site)
+}
@@ -0,0 +1,65 @@
+package send
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+)
+
+// Flusher is implemented by Senders that hold messages before
+// delivering them, such as the buffered and buildlogger Senders.
+// Flush delivers any pending messages, and returns once they have
+// been delivered, or when the context is done.
+type Flusher interface {
+	Flush(context.Context) error
+}
+
+// Flush is a helper function that flushes all of the Senders in a tree
+// of Senders. If the Sender implements Flusher, Flush calls its Flush
+// method, which is responsible for flushing the Senders that it sends
+// messages to; otherwise, Flush flushes the Sender's members (see
+// Members), in parallel. Senders that do not hold messages and do not
+// have members are not affected.
+func Flush(ctx context.Context, s Sender) error {
+	if f, ok := s.(Flusher); ok {
+		return f.Flush(ctx)
+	}
+
+	return flushAll(ctx, Members(s))
+}
+
+// flushAll flushes the Senders in parallel, and returns an error that
+// combines the errors from all of the Senders.
+func flushAll(ctx context.Context, senders []Sender) error {
+	switch len(senders) {
+	case 0:
+		return nil
+	case 1:
+		return Flush(ctx, senders[0])
+	}
+
+	errs := make([]error, len(senders))
+	wg := &sync.WaitGroup{}
+	for idx := range senders {
+		wg.Add(1)
+		go func(idx int) {
+			defer wg.Done()
+			errs[idx] = Flush(ctx, senders[idx])
+		}(idx)
+	}
+	wg.Wait()
+
+	msgs := []string{}
+	for _, err := range errs {
+		if err != nil {
+			msgs = append(msgs, err.Error())
+		}
+	}
+
+	if len(msgs) > 0 {
+		return errors.New(strings.Join(msgs, "\n"))
+	}
+
+	return nil
+}
@@ -0,0 +1,115 @@
+package send
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mongodb/grip/level"
+	"github.com/mongodb/grip/message"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBufferedSenderFlush(t *testing.T) {
+	assert := assert.New(t)
+
+	sink, err := NewInternalLogger("sink", LevelInfo{level.Info, level.Info})
+	assert.NoError(err)
+
+	s := NewBufferedSender(sink, time.Hour, 100)
+	for i := 0; i < 3; i++ {
+		s.Send(message.NewDefaultMessage(level.Info, "buffered"))
+	}
+	assert.Equal(0, sink.Len())
+
+	assert.NoError(Flush(context.Background(), s))
+	assert.Equal(1, sink.Len())
+	group, ok := sink.GetMessage().Message.(*message.GroupComposer)
+	assert.True(ok)
+	assert.Len(group.Messages(), 3)
+
+	// flushing an empty buffer does nothing
+	assert.NoError(Flush(context.Background(), s))
+	assert.Equal(0, sink.Len())
+
+	// closing sends the buffered messages
+	s.Send(message.NewDefaultMessage(level.Info, "buffered"))
+	assert.NoError(s.Close())
+	assert.Equal(1, sink.Len())
+	assert.Equal("buffered", sink.GetMessage().Rendered)
+
+	// flushing a closed sender is not an error
+	assert.NoError(Flush(context.Background(), s))
+}
+
+func TestBufferedSenderSendContextKeepsOrder(t *testing.T) {
+	assert := assert.New(t)
+
+	sink, err := NewInternalLogger("sink", LevelInfo{level.Info, level.Info})
+	assert.NoError(err)
+
+	s := NewBufferedSender(sink, time.Hour, 100)
+	s.Send(message.NewDefaultMessage(level.Info, "first"))
+	s.Send(message.NewDefaultMessage(level.Info, "second"))
+	assert.NoError(SendContext(context.Background(), s, message.NewDefaultMessage(level.Info, "third")))
+
+	// the buffered messages are sent before the message
+	assert.Equal(2, sink.Len())
+	group, ok := sink.GetMessage().Message.(*message.GroupComposer)
+	if assert.True(ok) {
+		assert.Len(group.Messages(), 2)
+	}
+	assert.Equal("third", sink.GetMessage().Rendered)
+	assert.NoError(s.Close())
+}
+
+func TestFlushWalksSenderTree(t *testing.T) {
+	assert := assert.New(t)
+	l := LevelInfo{level.Info, level.Info}
+
+	one, err := NewInternalLogger("one", l)
+	assert.NoError(err)
+	two, err := NewInternalLogger("two", l)
+	assert.NoError(err)
+
+	dedup := NewDedupSender(NewBufferedSender(two, time.Hour, 100), time.Hour)
+	multi := NewConfiguredMultiSender(NewBufferedSender(one, time.Hour, 100), dedup)
+	root := NewSamplingSender(multi)
+
+	for i := 0; i < 3; i++ {
+		root.Send(message.NewDefaultMessage(level.Info, "repeated"))
+	}
+	assert.Equal(0, one.Len())
+	assert.Equal(0, two.Len())
+
+	assert.NoError(Flush(context.Background(), root))
+	assert.Equal(1, one.Len())
+	assert.Len(one.GetMessage().Message.(*message.GroupComposer).Messages(), 3)
+
+	// the dedup sender sent the first message and the summary of
+	// the repeats to its buffered sender before it was flushed.
+	assert.Equal(1, two.Len())
+	msgs := two.GetMessage().Message.(*message.GroupComposer).Messages()
+	assert.Len(msgs, 2)
+	assert.Equal(2, msgs[1].Raw().(message.Fields)["repeated"])
+}
+
+func TestFlushHonorsContext(t *testing.T) {
+	assert := assert.New(t)
+
+	blocking := newFlakySender()
+	blocking.delay = time.Second
+
+	s := NewBufferedSender(blocking, time.Hour, 100)
+	s.Send(message.NewDefaultMessage(level.Info, "slow"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	assert.Equal(context.DeadlineExceeded, Flush(ctx, s))
+	assert.True(time.Since(start) < time.Second)
+
+	// senders without messages or members have nothing to flush
+	assert.NoError(Flush(ctx, MakeInternalLogger()))
+}
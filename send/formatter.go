@@ -60,11 +60,20 @@ func MakePlainFormatter() MessageFormatter {
 //
 //     [p=<levvel>] [<fileName>:<lineNumber>]: <message>
 //
-// It can never error.
+// The formatter uses the call site of the message, if it's known (see
+// message.GetCallSite), and otherwise the caller at the specified
+// depth, relative to the formatter. It can never error.
 func MakeCallSiteFormatter(depth int) MessageFormatter {
 	depth++
 	return func(m message.Composer) (string, error) {
-		file, line := callerInfo(depth)
+		var file string
+		var line int
+		if frame, ok := message.GetCallSite(m); ok {
+			file, line = shortFileName(frame.File), frame.Line
+		} else {
+			file, line = callerInfo(depth)
+		}
+
 		return fmt.Sprintf(callSiteTmpl, m.Priority(), file, line, m), nil
 	}
 }
@@ -88,9 +97,12 @@ func callerInfo(depth int) (string, int) {
 	// get caller info.
 	_, file, line, _ := runtime.Caller(depth)
 
-	// get the directory and filename
-	dir, fileName := filepath.Split(file)
-	file = filepath.Join(filepath.Base(dir), fileName)
+	return shortFileName(file), line
+}
 
-	return file, line
+// shortFileName returns the name of the file and its enclosing
+// directory.
+func shortFileName(file string) string {
+	dir, fileName := filepath.Split(file)
+	return filepath.Join(filepath.Base(dir), fileName)
 }
@@ -1,7 +1,9 @@
 package send
 
 import (
+	"context"
 	"errors"
+	"sync"
 
 	"github.com/mongodb/grip/level"
 	"github.com/mongodb/grip/message"
@@ -16,6 +18,8 @@ type InternalSender struct {
 	name   string
 	level  LevelInfo
 	output chan *InternalMessage
+	stats  *senderStats
+	mutex  sync.RWMutex
 }
 
 // InternalMessage provides a complete representation of all
@@ -50,13 +54,14 @@ func NewInternalLogger(name string, l LevelInfo) (*InternalSender, error) {
 func MakeInternalLogger() *InternalSender {
 	return &InternalSender{
 		output: make(chan *InternalMessage, 100),
+		stats:  newSenderStats(),
 	}
 }
 
 func (s *InternalSender) Name() string                          { return s.name }
 func (s *InternalSender) SetName(n string)                      { s.name = n }
 func (s *InternalSender) Close() error                          { close(s.output); return nil }
-func (s *InternalSender) Level() LevelInfo                      { return s.level }
+func (s *InternalSender) Stats() Stats                          { return s.stats.snapshot(s.name) }
 func (s *InternalSender) SetErrorHandler(_ ErrorHandler) error  { return nil }
 func (s *InternalSender) SetFormatter(_ MessageFormatter) error { return nil }
 func (s *InternalSender) SetLevel(l LevelInfo) error {
@@ -64,10 +69,20 @@ func (s *InternalSender) SetLevel(l LevelInfo) error {
 		return errors.New("invalid level")
 	}
 
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
 	s.level = l
 	return nil
 }
 
+func (s *InternalSender) Level() LevelInfo {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return s.level
+}
+
 // GetMessage pops the first message in the queue and returns.
 func (s *InternalSender) GetMessage() *InternalMessage {
 	return <-s.output
@@ -86,10 +101,31 @@ func (s *InternalSender) Len() int { return len(s.output) }
 // messages are sent, but the InternalMessage format tracks
 // "loggability" for testing purposes.
 func (s *InternalSender) Send(m message.Composer) {
-	s.output <- &InternalMessage{
+	_ = s.SendContext(context.Background(), m)
+}
+
+// SendContext adds the message to the internal queue, and returns
+// the context's error if the context is done before there is room in
+// the queue.
+func (s *InternalSender) SendContext(ctx context.Context, m message.Composer) error {
+	logged := s.Level().ShouldLog(m)
+
+	select {
+	case s.output <- &InternalMessage{
 		Message:  m,
 		Priority: m.Priority(),
 		Rendered: m.String(),
-		Logged:   s.level.ShouldLog(m),
+		Logged:   logged,
+	}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	if logged {
+		s.stats.recordSent(m.Priority(), 0)
+	} else {
+		s.stats.recordFiltered()
 	}
+
+	return nil
 }
@@ -1,7 +1,6 @@
 package send
 
 import (
-	"fmt"
 	"log"
 	"os"
 )
@@ -50,10 +49,11 @@ func MakeJSONFileLogger(file string) (Sender, error) {
 		return nil, err
 	}
 
-	f, err := os.OpenFile(file, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0666)
+	f, err := openLogFile(file)
 	if err != nil {
-		return nil, fmt.Errorf("error opening logging file, %s", err.Error())
+		return nil, err
 	}
+	s.file = f
 
 	s.logger = log.New(f, "", 0)
 
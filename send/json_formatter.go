@@ -0,0 +1,320 @@
+package send
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mongodb/grip/level"
+	"github.com/mongodb/grip/message"
+)
+
+// JSONSchema names a layout of the documents that formatters that
+// NewJSONFormatter creates produce.
+type JSONSchema string
+
+// The schemas that NewJSONFormatter supports:
+//
+//    JSONSchemaDefault: "time", "level", "logger", "message", and
+//        "source" (an object with "file", "line", and "function").
+//    JSONSchemaECS: the Elastic Common Schema, with "@timestamp",
+//        "log.level", "log.logger", "message", "ecs.version", and
+//        "log.origin.file.name", "log.origin.file.line", and
+//        "log.origin.function".
+//    JSONSchemaLogstash: the Logstash JSON event format, with
+//        "@timestamp", "@version", "level" (in upper case),
+//        "level_value", "logger_name", "message", and
+//        "caller_file_name", "caller_line_number", and
+//        "caller_method_name".
+//    JSONSchemaGCP: the structured logging format of Google Cloud
+//        Logging, with "time", "severity", "logger", "message", and
+//        "logging.googleapis.com/sourceLocation".
+const (
+	JSONSchemaDefault  JSONSchema = "default"
+	JSONSchemaECS      JSONSchema = "ecs"
+	JSONSchemaLogstash JSONSchema = "logstash"
+	JSONSchemaGCP      JSONSchema = "gcp"
+)
+
+const ecsVersion = "1.6.0"
+
+// JSONFormatterOptions configures the formatters that NewJSONFormatter
+// creates.
+type JSONFormatterOptions struct {
+	// Schema is the layout of the documents, and defaults to
+	// JSONSchemaDefault.
+	Schema JSONSchema
+
+	// Name is the logger name of messages that do not have a
+	// "logger" field.
+	Name string
+
+	// TimeFormat is the layout of timestamps, as in
+	// time.Time.Format, or "unix", "unix_ms", or "unix_ns" for
+	// numeric timestamps; it defaults to time.RFC3339Nano.
+	TimeFormat string
+
+	// FieldsKey, if set, is the key of an object that contains the
+	// fields of message.Fields messages. By default, fields are
+	// added to the top level of the document, unless they conflict
+	// with keys in the schema.
+	FieldsKey string
+
+	// Keys renames keys in the documents, after the formatter
+	// applies the schema: each key in the map is replaced by its
+	// value, or removed if its value is empty.
+	Keys map[string]string
+}
+
+// NewJSONFormatter returns a MessageFormatter that renders messages as
+// JSON documents with a stable layout, regardless of the type of the
+// message: the formatter uses the priority and string form of all
+// messages, the fields of message.Fields messages (and their "time",
+// "msg", and "logger" fields), and the call site of the message (see
+// message.GetCallSite) as the location of the message in the source.
+// Use the schema presets to produce documents for common log
+// processing systems.
+//
+// Returns an error if the schema is not known. The formatter returns
+// an error if the fields of a message cannot be rendered as JSON.
+func NewJSONFormatter(opts JSONFormatterOptions) (MessageFormatter, error) {
+	if opts.Schema == "" {
+		opts.Schema = JSONSchemaDefault
+	}
+
+	var layout func(*jsonEnvelope) map[string]interface{}
+	switch opts.Schema {
+	case JSONSchemaDefault:
+		layout = (*jsonEnvelope).defaultLayout
+	case JSONSchemaECS:
+		layout = (*jsonEnvelope).ecsLayout
+	case JSONSchemaLogstash:
+		layout = (*jsonEnvelope).logstashLayout
+	case JSONSchemaGCP:
+		layout = (*jsonEnvelope).gcpLayout
+	default:
+		return nil, fmt.Errorf("json schema '%s' is not supported", opts.Schema)
+	}
+
+	for k := range opts.Keys {
+		if k == "" {
+			return nil, errors.New("cannot rename an empty key")
+		}
+	}
+
+	return func(m message.Composer) (string, error) {
+		env := newJSONEnvelope(opts, m)
+		doc := layout(env)
+
+		if len(env.fields) > 0 {
+			if opts.FieldsKey != "" {
+				doc[opts.FieldsKey] = env.fields
+			} else {
+				for k, v := range env.fields {
+					if _, ok := doc[k]; !ok {
+						doc[k] = v
+					}
+				}
+			}
+		}
+
+		for from, to := range opts.Keys {
+			v, ok := doc[from]
+			if !ok {
+				continue
+			}
+			delete(doc, from)
+			if to != "" {
+				doc[to] = v
+			}
+		}
+
+		out, err := json.Marshal(doc)
+		if err != nil {
+			return "", err
+		}
+
+		return string(out), nil
+	}, nil
+}
+
+// jsonEnvelope holds the parts of a message that are common to all
+// types of messages.
+type jsonEnvelope struct {
+	opts     JSONFormatterOptions
+	time     time.Time
+	priority level.Priority
+	logger   string
+	message  string
+	fields   message.Fields
+	source   *message.StackFrame
+}
+
+func newJSONEnvelope(opts JSONFormatterOptions, m message.Composer) *jsonEnvelope {
+	env := &jsonEnvelope{
+		opts:     opts,
+		priority: m.Priority(),
+		logger:   opts.Name,
+		message:  m.String(),
+	}
+
+	switch raw := m.Raw().(type) {
+	case message.Fields:
+		env.fields = message.Fields{}
+		for k, v := range raw {
+			switch k {
+			case "time":
+				if t, ok := v.(time.Time); ok {
+					env.time = t
+					continue
+				}
+			case "msg":
+				env.message = fmt.Sprint(v)
+				continue
+			case "logger":
+				if name, ok := v.(string); ok && name != "" {
+					env.logger = name
+					continue
+				}
+			}
+			env.fields[k] = v
+		}
+	case message.StackTrace:
+		env.time = raw.Time
+	}
+
+	if frame, ok := message.GetCallSite(m); ok {
+		env.source = &frame
+	}
+
+	if env.time.IsZero() {
+		env.time = time.Now()
+	}
+
+	return env
+}
+
+func (e *jsonEnvelope) timestamp() interface{} {
+	switch e.opts.TimeFormat {
+	case "":
+		return e.time.Format(time.RFC3339Nano)
+	case "unix":
+		return e.time.Unix()
+	case "unix_ms":
+		return e.time.UnixNano() / int64(time.Millisecond)
+	case "unix_ns":
+		return e.time.UnixNano()
+	default:
+		return e.time.Format(e.opts.TimeFormat)
+	}
+}
+
+// setJSONValue adds the value to the document, unless it is empty.
+func setJSONValue(doc map[string]interface{}, key, value string) {
+	if value == "" {
+		return
+	}
+	doc[key] = value
+}
+
+func (e *jsonEnvelope) defaultLayout() map[string]interface{} {
+	doc := map[string]interface{}{
+		"time":    e.timestamp(),
+		"level":   e.priority.String(),
+		"message": e.message,
+	}
+	setJSONValue(doc, "logger", e.logger)
+
+	if e.source != nil {
+		doc["source"] = map[string]interface{}{
+			"file":     e.source.File,
+			"line":     e.source.Line,
+			"function": e.source.Function,
+		}
+	}
+
+	return doc
+}
+
+func (e *jsonEnvelope) ecsLayout() map[string]interface{} {
+	doc := map[string]interface{}{
+		"@timestamp":  e.timestamp(),
+		"log.level":   e.priority.String(),
+		"message":     e.message,
+		"ecs.version": ecsVersion,
+	}
+	setJSONValue(doc, "log.logger", e.logger)
+
+	if e.source != nil {
+		doc["log.origin.file.name"] = e.source.File
+		doc["log.origin.file.line"] = e.source.Line
+		doc["log.origin.function"] = e.source.Function
+	}
+
+	return doc
+}
+
+func (e *jsonEnvelope) logstashLayout() map[string]interface{} {
+	doc := map[string]interface{}{
+		"@timestamp":  e.timestamp(),
+		"@version":    "1",
+		"level":       strings.ToUpper(e.priority.String()),
+		"level_value": int(e.priority),
+		"message":     e.message,
+	}
+	setJSONValue(doc, "logger_name", e.logger)
+
+	if e.source != nil {
+		doc["caller_file_name"] = e.source.File
+		doc["caller_line_number"] = e.source.Line
+		doc["caller_method_name"] = e.source.Function
+	}
+
+	return doc
+}
+
+func (e *jsonEnvelope) gcpLayout() map[string]interface{} {
+	doc := map[string]interface{}{
+		"time":     e.timestamp(),
+		"severity": gcpSeverity(e.priority),
+		"message":  e.message,
+	}
+	setJSONValue(doc, "logger", e.logger)
+
+	if e.source != nil {
+		doc["logging.googleapis.com/sourceLocation"] = map[string]interface{}{
+			"file":     e.source.File,
+			"line":     strconv.Itoa(e.source.Line),
+			"function": e.source.Function,
+		}
+	}
+
+	return doc
+}
+
+// gcpSeverity converts a priority to a Google Cloud Logging severity.
+func gcpSeverity(p level.Priority) string {
+	switch {
+	case p >= level.Emergency:
+		return "EMERGENCY"
+	case p >= level.Alert:
+		return "ALERT"
+	case p >= level.Critical:
+		return "CRITICAL"
+	case p >= level.Error:
+		return "ERROR"
+	case p >= level.Warning:
+		return "WARNING"
+	case p >= level.Notice:
+		return "NOTICE"
+	case p >= level.Info:
+		return "INFO"
+	case p >= level.Trace:
+		return "DEBUG"
+	default:
+		return "DEFAULT"
+	}
+}
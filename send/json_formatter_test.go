@@ -0,0 +1,168 @@
+package send
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mongodb/grip/level"
+	"github.com/mongodb/grip/message"
+	"github.com/stretchr/testify/assert"
+)
+
+func formatJSONDoc(t *testing.T, fm MessageFormatter, m message.Composer) map[string]interface{} {
+	out, err := fm(m)
+	assert.NoError(t, err)
+
+	doc := map[string]interface{}{}
+	assert.NoError(t, json.Unmarshal([]byte(out), &doc), out)
+
+	return doc
+}
+
+func TestJSONFormatterStableEnvelope(t *testing.T) {
+	assert := assert.New(t)
+	ts := time.Date(2018, time.March, 4, 5, 6, 7, 0, time.UTC)
+
+	fm, err := NewJSONFormatter(JSONFormatterOptions{Name: "app"})
+	assert.NoError(err)
+
+	fromFields, err := fm(message.NewFieldsMessage(level.Warning, "disk is full", message.Fields{"time": ts}))
+	assert.NoError(err)
+	assert.Equal(`{"level":"warning","logger":"app","message":"disk is full","time":"2018-03-04T05:06:07Z"}`, fromFields)
+
+	// other types of messages have the same layout
+	for _, m := range []message.Composer{
+		message.NewDefaultMessage(level.Warning, "disk is full"),
+		message.NewFormattedMessage(level.Warning, "disk is %s", "full"),
+		message.NewErrorMessage(level.Warning, errors.New("disk is full")),
+	} {
+		doc := formatJSONDoc(t, fm, m)
+		assert.Len(doc, 4)
+		assert.Equal("warning", doc["level"])
+		assert.Equal("app", doc["logger"])
+		assert.Equal("disk is full", doc["message"])
+		assert.Contains(doc, "time")
+	}
+
+	doc := formatJSONDoc(t, fm, message.NewFieldsMessage(level.Info, "started", message.Fields{
+		"logger":  "worker",
+		"port":    8080,
+		"message": "conflicts",
+	}))
+	assert.Equal("worker", doc["logger"])
+	assert.Equal("started", doc["message"])
+	assert.Equal(8080.0, doc["port"])
+
+	m := message.NewStack(1, "stacked")
+	assert.NoError(m.SetPriority(level.Error))
+	doc = formatJSONDoc(t, fm, m)
+	source, ok := doc["source"].(map[string]interface{})
+	if assert.True(ok) {
+		assert.Equal("github.com/mongodb/grip/send.TestJSONFormatterStableEnvelope", source["function"])
+		assert.Contains(source["file"], "json_formatter_test.go")
+	}
+
+	// call sites that the Journaler records are used for all messages
+	plain := message.NewDefaultMessage(level.Info, "hello")
+	plain.(message.CallSiteComposer).SetCallSite(message.StackFrame{Function: "main.main", File: "main.go", Line: 7})
+	doc = formatJSONDoc(t, fm, plain)
+	assert.Equal(map[string]interface{}{"function": "main.main", "file": "main.go", "line": 7.0}, doc["source"])
+
+	out, err := MakeCallSiteFormatter(0)(plain)
+	assert.NoError(err)
+	assert.Equal("[p=info] [main.go:7]: hello", out)
+}
+
+func TestJSONFormatterSchemas(t *testing.T) {
+	assert := assert.New(t)
+	ts := time.Date(2018, time.March, 4, 5, 6, 7, 0, time.UTC)
+	m := message.NewFieldsMessage(level.Notice, "hello", message.Fields{"time": ts, "logger": "app", "user": "jane"})
+
+	fm, err := NewJSONFormatter(JSONFormatterOptions{Schema: JSONSchemaECS})
+	assert.NoError(err)
+	assert.Equal(map[string]interface{}{
+		"@timestamp":  "2018-03-04T05:06:07Z",
+		"log.level":   "notice",
+		"log.logger":  "app",
+		"message":     "hello",
+		"ecs.version": ecsVersion,
+		"user":        "jane",
+	}, formatJSONDoc(t, fm, m))
+
+	fm, err = NewJSONFormatter(JSONFormatterOptions{Schema: JSONSchemaLogstash, TimeFormat: "unix"})
+	assert.NoError(err)
+	assert.Equal(map[string]interface{}{
+		"@timestamp":  float64(ts.Unix()),
+		"@version":    "1",
+		"level":       "NOTICE",
+		"level_value": 50.0,
+		"logger_name": "app",
+		"message":     "hello",
+		"user":        "jane",
+	}, formatJSONDoc(t, fm, m))
+
+	fm, err = NewJSONFormatter(JSONFormatterOptions{Schema: JSONSchemaGCP, FieldsKey: "labels"})
+	assert.NoError(err)
+	assert.Equal(map[string]interface{}{
+		"time":     "2018-03-04T05:06:07Z",
+		"severity": "NOTICE",
+		"logger":   "app",
+		"message":  "hello",
+		"labels":   map[string]interface{}{"user": "jane"},
+	}, formatJSONDoc(t, fm, m))
+
+	stack := message.NewStack(1, "stacked")
+	assert.NoError(stack.SetPriority(level.Trace))
+	doc := formatJSONDoc(t, fm, stack)
+	assert.Equal("DEBUG", doc["severity"])
+	location, ok := doc["logging.googleapis.com/sourceLocation"].(map[string]interface{})
+	if assert.True(ok) {
+		assert.IsType("", location["line"])
+	}
+
+	_, err = NewJSONFormatter(JSONFormatterOptions{Schema: "splunk"})
+	assert.Error(err)
+}
+
+func TestJSONFormatterKeysAndTime(t *testing.T) {
+	assert := assert.New(t)
+	ts := time.Date(2018, time.March, 4, 5, 6, 7, 0, time.UTC)
+	m := message.NewFieldsMessage(level.Info, "hello", message.Fields{"time": ts, "user": "jane"})
+
+	fm, err := NewJSONFormatter(JSONFormatterOptions{
+		TimeFormat: "2006-01-02",
+		Keys:       map[string]string{"message": "msg", "user": "user.name", "level": ""},
+	})
+	assert.NoError(err)
+	assert.Equal(map[string]interface{}{
+		"time":      "2018-03-04",
+		"msg":       "hello",
+		"user.name": "jane",
+	}, formatJSONDoc(t, fm, m))
+
+	fm, err = NewJSONFormatter(JSONFormatterOptions{TimeFormat: "unix_ms"})
+	assert.NoError(err)
+	assert.Equal(float64(ts.UnixNano()/int64(time.Millisecond)), formatJSONDoc(t, fm, m)["time"])
+
+	_, err = NewJSONFormatter(JSONFormatterOptions{Keys: map[string]string{"": "x"}})
+	assert.Error(err)
+
+	_, err = fm(message.NewFields(level.Info, message.Fields{"bad": make(chan int)}))
+	assert.Error(err)
+
+	factory, ok := getFormatterFactory("json")
+	if assert.True(ok) {
+		fm, err = factory(&FormatterConfig{Type: "json", Options: map[string]interface{}{"schema": "ecs"}})
+		assert.NoError(err)
+		assert.Equal("info", formatJSONDoc(t, fm, m)["log.level"])
+
+		_, err = factory(&FormatterConfig{Type: "json", Options: map[string]interface{}{"schema": "nope"}})
+		assert.Error(err)
+
+		fm, err = factory(&FormatterConfig{Type: "json"})
+		assert.NoError(err)
+		assert.Equal("hello", formatJSONDoc(t, fm, m)["msg"])
+	}
+}
@@ -0,0 +1,340 @@
+package send
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mongodb/grip/level"
+	"github.com/mongodb/grip/message"
+)
+
+// JUnitOptions configures a JUnitSender.
+type JUnitOptions struct {
+	// Path is the file that the Sender writes the report to.
+	Path string
+
+	// Suite is the name of the test suite in the report, and
+	// defaults to the name of the Sender.
+	Suite string
+
+	// TestField is the name of the field that holds the name of
+	// the test in message.Fields messages, and defaults to "test".
+	TestField string
+
+	// ResultField is the name of the field that holds the result
+	// of the test in result messages, and defaults to "result".
+	ResultField string
+
+	// FailureLevel is the lowest priority of messages that mark a
+	// test as failed, and defaults to level.Error.
+	FailureLevel level.Priority
+}
+
+// JUnitSender is a Sender that collects messages about tests and writes
+// a JUnit XML report, which most CI systems can display, when it's
+// closed or flushed. It provides the structure of the buildlogger
+// Sender (per-test logs) without a logkeeper server.
+//
+// The Sender groups message.Fields messages by the value of their test
+// field (TestField), in the order in which tests first appear, and
+// records the rendered messages as the output of the test. Tests with
+// messages at or above the failure level (FailureLevel) fail, and the
+// first such message is the failure message. Messages without a test
+// name are recorded as the output of the suite.
+//
+// To report the result of a test explicitly, send a message with a
+// result field (ResultField), whose value is "pass", "fail", "error",
+// or "skip", for example:
+//
+//     grip.Info(message.Fields{"test": "TestLogin", "result": "skip", "msg": "no database"})
+//
+// Explicit failures, errors, and skips take precedence over failures
+// from messages, but an explicit "pass" does not clear failures. The
+// duration of a test is its "duration" field (a time.Duration, or a
+// number of seconds), if set, and is otherwise the time between its
+// first and last messages.
+type JUnitSender struct {
+	opts   JUnitOptions
+	start  time.Time
+	tests  []*junitTest
+	byName map[string]*junitTest
+	output []string
+	closed bool
+	lock   sync.Mutex
+	*Base
+}
+
+type junitTest struct {
+	name     string
+	first    time.Time
+	last     time.Time
+	duration time.Duration
+	result   string
+	failure  string
+	failures []string
+	output   []string
+}
+
+// NewJUnitSender constructs a JUnitSender that writes its report to the
+// specified file. Returns an error if the file is not specified, or
+// its directory does not exist.
+func NewJUnitSender(name string, l LevelInfo, opts JUnitOptions) (*JUnitSender, error) {
+	if opts.Path == "" {
+		return nil, errors.New("must specify a path for the junit report")
+	}
+
+	if _, err := os.Stat(filepath.Dir(opts.Path)); err != nil {
+		return nil, fmt.Errorf("problem with the directory of the junit report: %s", err.Error())
+	}
+
+	if opts.TestField == "" {
+		opts.TestField = "test"
+	}
+
+	if opts.ResultField == "" {
+		opts.ResultField = "result"
+	}
+
+	if opts.FailureLevel == level.Invalid {
+		opts.FailureLevel = level.Error
+	}
+
+	s := &JUnitSender{
+		opts:   opts,
+		start:  time.Now(),
+		byName: map[string]*junitTest{},
+		Base:   NewBase(name),
+	}
+
+	if err := s.SetFormatter(MakeDefaultFormatter()); err != nil {
+		return nil, err
+	}
+
+	if err := s.SetLevel(l); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (s *JUnitSender) Send(m message.Composer) {
+	if !s.shouldLog(m) {
+		return
+	}
+	start := time.Now()
+
+	out, err := s.formatter(m)
+	if err != nil {
+		s.errHandler(err, m)
+		return
+	}
+	defer s.recordSend(m, start)
+
+	now := time.Now()
+	fields, _ := m.Raw().(message.Fields)
+	name, _ := fields[s.opts.TestField].(string)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if name == "" {
+		s.output = append(s.output, out)
+		return
+	}
+
+	test, ok := s.byName[name]
+	if !ok {
+		test = &junitTest{name: name, first: now}
+		s.byName[name] = test
+		s.tests = append(s.tests, test)
+	}
+	test.last = now
+
+	result, isResult := fields[s.opts.ResultField]
+	if isResult {
+		text, _ := fields["msg"].(string)
+		switch r := strings.ToLower(fmt.Sprint(result)); r {
+		case "pass", "passed", "success":
+			if test.result == "" {
+				test.result = "pass"
+			}
+		case "fail", "failed", "failure":
+			test.result = "fail"
+			if test.failure == "" {
+				test.failure = text
+			}
+		case "error":
+			test.result = "error"
+			test.failure = text
+		case "skip", "skipped":
+			test.result = "skip"
+			test.failure = text
+		default:
+			s.errHandler(fmt.Errorf("result '%s' of test '%s' is not valid", r, name), m)
+		}
+
+		if d, ok := junitDuration(fields["duration"]); ok {
+			test.duration = d
+		}
+	}
+
+	if m.Priority() >= s.opts.FailureLevel {
+		test.failures = append(test.failures, out)
+	}
+
+	// result messages without text only record the result.
+	if isResult && fields["msg"] == "" {
+		return
+	}
+	test.output = append(test.output, out)
+}
+
+// junitDuration converts the value of a duration field to a duration.
+func junitDuration(v interface{}) (time.Duration, bool) {
+	switch d := v.(type) {
+	case time.Duration:
+		return d, true
+	case int:
+		return time.Duration(d) * time.Second, true
+	case int64:
+		return time.Duration(d) * time.Second, true
+	case float64:
+		return time.Duration(d * float64(time.Second)), true
+	case string:
+		if dur, err := time.ParseDuration(d); err == nil {
+			return dur, true
+		}
+		if secs, err := strconv.ParseFloat(d, 64); err == nil {
+			return time.Duration(secs * float64(time.Second)), true
+		}
+	}
+
+	return 0, false
+}
+
+// Flush writes the report, with the messages received so far, so that
+// a report exists even if the process exits before closing the Sender.
+func (s *JUnitSender) Flush(_ context.Context) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.write()
+}
+
+// Close writes the report. Subsequent calls to Close do nothing.
+func (s *JUnitSender) Close() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+
+	return s.write()
+}
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+	SystemOut string          `xml:"system-out,omitempty"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitProblem `xml:"failure,omitempty"`
+	Error     *junitProblem `xml:"error,omitempty"`
+	Skipped   *junitProblem `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitProblem struct {
+	Message string `xml:"message,attr"`
+	Body    string `xml:",chardata"`
+}
+
+// write writes the report to a temporary file, and then renames it, so
+// that readers never see a partial report; the caller must hold the
+// lock.
+func (s *JUnitSender) write() error {
+	suite := junitTestSuite{
+		Name:      s.opts.Suite,
+		Tests:     len(s.tests),
+		Time:      junitSeconds(time.Since(s.start)),
+		Timestamp: s.start.Format("2006-01-02T15:04:05"),
+		SystemOut: strings.Join(s.output, "\n"),
+	}
+	if suite.Name == "" {
+		suite.Name = s.Name()
+	}
+
+	for _, test := range s.tests {
+		tc := junitTestCase{
+			Name:      test.name,
+			ClassName: suite.Name,
+			Time:      junitSeconds(test.last.Sub(test.first)),
+			SystemOut: strings.Join(test.output, "\n"),
+		}
+		if test.duration > 0 {
+			tc.Time = junitSeconds(test.duration)
+		}
+
+		switch {
+		case test.result == "skip":
+			tc.Skipped = &junitProblem{Message: test.failure}
+			suite.Skipped++
+		case test.result == "error":
+			tc.Error = &junitProblem{Message: test.failure, Body: strings.Join(test.failures, "\n")}
+			suite.Errors++
+		case test.result == "fail" || len(test.failures) > 0:
+			msg := test.failure
+			if msg == "" && len(test.failures) > 0 {
+				msg = test.failures[0]
+			} else if msg == "" {
+				msg = "failed"
+			}
+			tc.Failure = &junitProblem{Message: msg, Body: strings.Join(test.failures, "\n")}
+			suite.Failures++
+		}
+
+		suite.Cases = append(suite.Cases, tc)
+	}
+
+	out, err := xml.MarshalIndent(junitTestSuites{Suites: []junitTestSuite{suite}}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := s.opts.Path + ".tmp"
+	if err = ioutil.WriteFile(tmp, append([]byte(xml.Header), append(out, '\n')...), 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, s.opts.Path)
+}
+
+func junitSeconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', 3, 64)
+}
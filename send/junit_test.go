@@ -0,0 +1,158 @@
+package send
+
+import (
+	"context"
+	"encoding/xml"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mongodb/grip/level"
+	"github.com/mongodb/grip/message"
+	"github.com/stretchr/testify/assert"
+)
+
+func readJUnitReport(t *testing.T, path string) junitTestSuite {
+	data, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+
+	report := junitTestSuites{}
+	assert.NoError(t, xml.Unmarshal(data, &report))
+	if !assert.Len(t, report.Suites, 1) {
+		t.FailNow()
+	}
+
+	return report.Suites[0]
+}
+
+func TestJUnitSender(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "grip-junit")
+	assert.NoError(err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "report.xml")
+
+	s, err := NewJUnitSender("tests", LevelInfo{level.Info, level.Info}, JUnitOptions{Path: path})
+	assert.NoError(err)
+	assert.NoError(s.SetFormatter(MakePlainFormatter()))
+
+	s.Send(message.NewDefaultMessage(level.Info, "starting"))
+	s.Send(message.NewFieldsMessage(level.Info, "connecting", message.Fields{"test": "TestConnect"}))
+	s.Send(message.NewFieldsMessage(level.Info, "logging in", message.Fields{"test": "TestLogin"}))
+	s.Send(message.NewFieldsMessage(level.Error, "bad password", message.Fields{"test": "TestLogin"}))
+	s.Send(message.NewFieldsMessage(level.Debug, "filtered", message.Fields{"test": "TestLogin"}))
+	s.Send(message.NewFields(level.Info, message.Fields{"test": "TestConnect", "result": "pass", "duration": 1.5}))
+	s.Send(message.NewFieldsMessage(level.Info, "no database", message.Fields{"test": "TestQuery", "result": "skip"}))
+	s.Send(message.NewFieldsMessage(level.Info, "timed out", message.Fields{"test": "TestSlow", "result": "fail", "duration": "2s"}))
+	s.Send(message.NewFields(level.Info, message.Fields{"test": "TestPanic", "result": "error", "msg": "panicked"}))
+	s.Send(message.NewFieldsMessage(level.Error, "after the pass", message.Fields{"test": "TestLater", "result": "pass"}))
+	s.Send(message.NewFields(level.Info, message.Fields{"test": "TestBare", "result": "fail"}))
+
+	// the report is written when the sender is flushed
+	assert.NoError(Flush(context.Background(), s))
+	suite := readJUnitReport(t, path)
+	assert.Equal("tests", suite.Name)
+	assert.Equal(7, suite.Tests)
+	assert.Equal(4, suite.Failures)
+	assert.Equal(1, suite.Errors)
+	assert.Equal(1, suite.Skipped)
+	assert.Equal("starting", suite.SystemOut)
+
+	cases := map[string]junitTestCase{}
+	order := []string{}
+	for _, tc := range suite.Cases {
+		cases[tc.Name] = tc
+		order = append(order, tc.Name)
+	}
+	assert.Equal([]string{"TestConnect", "TestLogin", "TestQuery", "TestSlow", "TestPanic", "TestLater", "TestBare"}, order)
+
+	connect := cases["TestConnect"]
+	assert.Nil(connect.Failure)
+	assert.Equal("1.500", connect.Time)
+	assert.Equal("tests", connect.ClassName)
+	assert.Contains(connect.SystemOut, "connecting")
+	assert.NotContains(connect.SystemOut, "result")
+
+	login := cases["TestLogin"]
+	if assert.NotNil(login.Failure) {
+		assert.Contains(login.Failure.Message, "bad password")
+	}
+	assert.Contains(login.SystemOut, "logging in")
+	assert.NotContains(login.SystemOut, "filtered")
+
+	if assert.NotNil(cases["TestQuery"].Skipped) {
+		assert.Equal("no database", cases["TestQuery"].Skipped.Message)
+	}
+
+	if assert.NotNil(cases["TestSlow"].Failure) {
+		assert.Equal("timed out", cases["TestSlow"].Failure.Message)
+	}
+	assert.Equal("2.000", cases["TestSlow"].Time)
+
+	if assert.NotNil(cases["TestPanic"].Error) {
+		assert.Equal("panicked", cases["TestPanic"].Error.Message)
+	}
+
+	// an explicit pass does not clear failures
+	assert.NotNil(cases["TestLater"].Failure)
+
+	// failures without text or failing messages have a generic message
+	if assert.NotNil(cases["TestBare"].Failure) {
+		assert.Equal("failed", cases["TestBare"].Failure.Message)
+	}
+
+	// messages after a flush are included when the sender closes
+	s.Send(message.NewFieldsMessage(level.Info, "more", message.Fields{"test": "TestAfterFlush"}))
+	assert.NoError(s.Close())
+	suite = readJUnitReport(t, path)
+	assert.Equal(8, suite.Tests)
+
+	// closing again does not rewrite the report
+	assert.NoError(os.Remove(path))
+	assert.NoError(s.Close())
+	_, err = os.Stat(path)
+	assert.True(os.IsNotExist(err))
+}
+
+func TestJUnitSenderOptions(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "grip-junit")
+	assert.NoError(err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "report.xml")
+
+	_, err = NewJUnitSender("tests", LevelInfo{level.Info, level.Info}, JUnitOptions{})
+	assert.Error(err)
+
+	_, err = NewJUnitSender("tests", LevelInfo{level.Info, level.Info}, JUnitOptions{Path: filepath.Join(dir, "missing", "report.xml")})
+	assert.Error(err)
+
+	sender, err := BuildSender(&SenderConfig{
+		Type: "junit",
+		Name: "config",
+		Options: map[string]interface{}{
+			"path":          path,
+			"suite":         "integration",
+			"test_field":    "case",
+			"result_field":  "status",
+			"failure_level": "warning",
+		},
+	})
+	if !assert.NoError(err) {
+		return
+	}
+
+	sender.Send(message.NewFieldsMessage(level.Warning, "slow", message.Fields{"case": "one"}))
+	sender.Send(message.NewFields(level.Info, message.Fields{"case": "two", "status": "skipped", "duration": time.Second}))
+	assert.NoError(sender.Close())
+
+	suite := readJUnitReport(t, path)
+	assert.Equal("integration", suite.Name)
+	assert.Equal(2, suite.Tests)
+	assert.Equal(1, suite.Failures)
+	assert.Equal(1, suite.Skipped)
+}
@@ -0,0 +1,249 @@
+package send
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/mongodb/grip/level"
+	"github.com/mongodb/grip/message"
+)
+
+// MakeLogfmtFormatter returns a MessageFormatter that renders messages
+// in the logfmt format, as used by many log processing pipelines:
+//
+//     time=<time> level=<priority> logger=<name> msg=<message> <key>=<value> ...
+//
+// The logger is the value of the "logger" field of messages whose Raw
+// form is a message.Fields value, or otherwise the name, and is
+// omitted if both are empty. For message.Fields messages, the message
+// is the "msg" field, and the remaining fields follow, sorted by key;
+// for other messages, the message is the string form of the message.
+// Values that contain spaces, quotes, equal signs, or other special
+// characters are quoted. It can never error.
+//
+// Use ParseLogfmtMessage to convert lines back into messages.
+func MakeLogfmtFormatter(name string) MessageFormatter {
+	return func(m message.Composer) (string, error) {
+		fields, isFields := m.Raw().(message.Fields)
+
+		ts, ok := fields["time"].(time.Time)
+		if !ok {
+			ts = time.Now()
+		}
+
+		logger := name
+		if v, ok := fields["logger"].(string); ok && v != "" {
+			logger = v
+		}
+
+		msg := m.String()
+		if isFields {
+			msg = ""
+			if v, ok := fields["msg"]; ok && v != nil {
+				msg = fmt.Sprint(v)
+			}
+		}
+
+		buf := &bytes.Buffer{}
+		writeLogfmtPair(buf, "time", ts.Format(time.RFC3339Nano))
+		writeLogfmtPair(buf, "level", m.Priority().String())
+		if logger != "" {
+			writeLogfmtPair(buf, "logger", logger)
+		}
+		writeLogfmtPair(buf, "msg", msg)
+
+		keys := make([]string, 0, len(fields))
+		for k := range fields {
+			switch k {
+			case "time", "level", "logger", "msg":
+				continue
+			}
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			writeLogfmtPair(buf, k, logfmtValue(fields[k]))
+		}
+
+		return buf.String(), nil
+	}
+}
+
+func writeLogfmtPair(buf *bytes.Buffer, key, value string) {
+	if buf.Len() > 0 {
+		buf.WriteByte(' ')
+	}
+
+	buf.WriteString(logfmtKey(key))
+	buf.WriteByte('=')
+
+	if logfmtNeedsQuotes(value) {
+		buf.WriteString(strconv.Quote(value))
+	} else {
+		buf.WriteString(value)
+	}
+}
+
+// logfmtKey replaces the characters that cannot appear in keys.
+func logfmtKey(key string) string {
+	if key == "" {
+		return "_"
+	}
+
+	return strings.Map(func(r rune) rune {
+		if r <= ' ' || r == '=' || r == '"' || !unicode.IsPrint(r) {
+			return '_'
+		}
+		return r
+	}, key)
+}
+
+func logfmtValue(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case time.Time:
+		return val.Format(time.RFC3339Nano)
+	case error:
+		return val.Error()
+	case fmt.Stringer:
+		return val.String()
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+func logfmtNeedsQuotes(value string) bool {
+	if value == "" {
+		return true
+	}
+
+	for _, r := range value {
+		if r <= ' ' || r == '=' || r == '"' || r == '\\' || !unicode.IsPrint(r) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ParseLogfmt parses a line in the logfmt format into a message.Fields
+// value, in which all values are strings. Keys without values (e.g.
+// "debug" in "msg=hello debug") have the value "true".
+func ParseLogfmt(line string) (message.Fields, error) {
+	out := message.Fields{}
+
+	pos := 0
+	for {
+		for pos < len(line) && line[pos] == ' ' {
+			pos++
+		}
+		if pos >= len(line) {
+			return out, nil
+		}
+
+		start := pos
+		for pos < len(line) && line[pos] != '=' && line[pos] != ' ' {
+			if line[pos] == '"' {
+				return nil, fmt.Errorf("logfmt: unexpected quote in key at column %d", pos+1)
+			}
+			pos++
+		}
+		key := line[start:pos]
+
+		if key == "" {
+			return nil, fmt.Errorf("logfmt: missing key at column %d", start+1)
+		}
+
+		if pos >= len(line) || line[pos] == ' ' {
+			out[key] = "true"
+			continue
+		}
+
+		// skip the '='
+		pos++
+
+		if pos < len(line) && line[pos] == '"' {
+			end, err := logfmtQuoteEnd(line, pos)
+			if err != nil {
+				return nil, fmt.Errorf("logfmt: value of '%s' at column %d: %s", key, pos+1, err.Error())
+			}
+
+			value, err := strconv.Unquote(line[pos : end+1])
+			if err != nil {
+				return nil, fmt.Errorf("logfmt: value of '%s' at column %d: %s", key, pos+1, err.Error())
+			}
+
+			out[key] = value
+			pos = end + 1
+
+			if pos < len(line) && line[pos] != ' ' {
+				return nil, fmt.Errorf("logfmt: unexpected character after quoted value at column %d", pos+1)
+			}
+			continue
+		}
+
+		start = pos
+		for pos < len(line) && line[pos] != ' ' {
+			if line[pos] == '"' || line[pos] == '=' {
+				return nil, fmt.Errorf("logfmt: unexpected '%c' in value of '%s' at column %d", line[pos], key, pos+1)
+			}
+			pos++
+		}
+		out[key] = line[start:pos]
+	}
+}
+
+// logfmtQuoteEnd returns the index of the quote that ends the quoted
+// value that starts at the index.
+func logfmtQuoteEnd(line string, start int) (int, error) {
+	for idx := start + 1; idx < len(line); idx++ {
+		switch line[idx] {
+		case '\\':
+			idx++
+		case '"':
+			return idx, nil
+		}
+	}
+
+	return 0, errors.New("unterminated quoted value")
+}
+
+// ParseLogfmtMessage parses a line in the logfmt format, as produced by
+// the logfmt formatter, into a message.Fields message. The "level"
+// field sets the priority of the message, which defaults to info, and
+// the "time" field is converted to a time.Time value, if it is in the
+// RFC 3339 format.
+func ParseLogfmtMessage(line string) (message.Composer, error) {
+	fields, err := ParseLogfmt(line)
+	if err != nil {
+		return nil, err
+	}
+
+	p := level.Info
+	if v, ok := fields["level"].(string); ok {
+		if p = level.FromString(v); p == level.Invalid {
+			return nil, fmt.Errorf("logfmt: level '%s' is not valid", v)
+		}
+		delete(fields, "level")
+	}
+
+	if v, ok := fields["time"].(string); ok {
+		if ts, err := time.Parse(time.RFC3339Nano, v); err == nil {
+			fields["time"] = ts
+		}
+	}
+
+	msg, _ := fields["msg"].(string)
+
+	return message.NewFieldsMessage(p, msg, fields), nil
+}
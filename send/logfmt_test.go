@@ -0,0 +1,114 @@
+package send
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mongodb/grip/level"
+	"github.com/mongodb/grip/message"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogfmtFormatter(t *testing.T) {
+	assert := assert.New(t)
+	ts := time.Date(2018, time.March, 4, 5, 6, 7, 8, time.UTC)
+
+	fm := MakeLogfmtFormatter("app")
+	out, err := fm(message.NewFieldsMessage(level.Warning, "disk is full", message.Fields{
+		"time":    ts,
+		"path":    "/var/lib/data",
+		"err":     errors.New("no space"),
+		"bytes":   42,
+		"empty":   "",
+		"quote":   `say "hi"`,
+		"lines":   "one\ntwo",
+		"equals":  "a=b",
+		"bad=key": "value",
+	}))
+	assert.NoError(err)
+	assert.Equal(`time=2018-03-04T05:06:07.000000008Z level=warning logger=app msg="disk is full" `+
+		`bad_key=value bytes=42 empty="" equals="a=b" err="no space" lines="one\ntwo" `+
+		`path=/var/lib/data quote="say \"hi\""`, out)
+
+	// the logger field overrides the name
+	out, err = fm(message.NewFieldsMessage(level.Info, "started", message.Fields{"time": ts, "logger": "worker"}))
+	assert.NoError(err)
+	assert.Equal("time=2018-03-04T05:06:07.000000008Z level=info logger=worker msg=started", out)
+
+	// other messages render their string form
+	out, err = MakeLogfmtFormatter("")(message.NewDefaultMessage(level.Error, "it broke"))
+	assert.NoError(err)
+	assert.True(strings.HasPrefix(out, "time="))
+	assert.True(strings.HasSuffix(out, ` level=error msg="it broke"`))
+	assert.NotContains(out, "logger=")
+}
+
+func TestLogfmtRoundTrip(t *testing.T) {
+	assert := assert.New(t)
+	ts := time.Date(2018, time.March, 4, 5, 6, 7, 0, time.UTC)
+
+	fm := MakeLogfmtFormatter("app")
+	line, err := fm(message.NewFieldsMessage(level.Alert, "tab\there", message.Fields{
+		"time":    ts,
+		"user":    "jane doe",
+		"unicode": "héllo",
+		"slash":   `C:\temp`,
+	}))
+	assert.NoError(err)
+
+	m, err := ParseLogfmtMessage(line)
+	if assert.NoError(err) {
+		assert.Equal(level.Alert, m.Priority())
+
+		fields := m.Raw().(message.Fields)
+		assert.Equal(ts, fields["time"])
+		assert.Equal("app", fields["logger"])
+		assert.Equal("tab\there", fields["msg"])
+		assert.Equal("jane doe", fields["user"])
+		assert.Equal("héllo", fields["unicode"])
+		assert.Equal(`C:\temp`, fields["slash"])
+		assert.NotContains(fields, "level")
+
+		again, err := fm(m)
+		assert.NoError(err)
+		assert.Equal(line, again)
+	}
+}
+
+func TestParseLogfmt(t *testing.T) {
+	assert := assert.New(t)
+
+	fields, err := ParseLogfmt(`  a=1 b="two words"  debug c= d=""`)
+	assert.NoError(err)
+	assert.Equal(message.Fields{"a": "1", "b": "two words", "debug": "true", "c": "", "d": ""}, fields)
+
+	fields, err = ParseLogfmt("")
+	assert.NoError(err)
+	assert.Len(fields, 0)
+
+	for line, problem := range map[string]string{
+		`a="open`:      "unterminated quoted value",
+		`=value`:       "missing key at column 1",
+		`a"b=c`:        "unexpected quote in key at column 2",
+		`a="b"c`:       "unexpected character after quoted value at column 6",
+		`a=b"c`:        `unexpected '"' in value of 'a' at column 4`,
+		`a=b=c`:        "unexpected '=' in value of 'a' at column 4",
+		`a="bad \q"`:   "value of 'a' at column 3",
+		`x=1 b="\"" =`: "missing key at column 12",
+	} {
+		_, err = ParseLogfmt(line)
+		if assert.Error(err, line) {
+			assert.Contains(err.Error(), problem, line)
+		}
+	}
+
+	m, err := ParseLogfmtMessage("msg=hi")
+	assert.NoError(err)
+	assert.Equal(level.Info, m.Priority())
+	assert.Equal("hi", m.Raw().(message.Fields)["msg"])
+
+	_, err = ParseLogfmtMessage("level=loud msg=hi")
+	assert.Error(err)
+}
@@ -1,9 +1,11 @@
 package send
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/mongodb/grip/level"
 	"github.com/mongodb/grip/message"
@@ -120,10 +122,35 @@ func (s *multiSender) Send(m message.Composer) {
 	// sender decide for itself, rather than short circuiting here
 	bl := s.Base.Level()
 	if bl.Valid() && !bl.ShouldLog(m) {
+		s.stats.recordFiltered()
 		return
 	}
+	defer s.recordSend(m, time.Now())
 
 	for _, sender := range s.senders {
 		sender.Send(m)
 	}
 }
+
+// SendContext sends the message to all of the member Senders, and
+// returns an error that combines the errors from all of the members.
+func (s *multiSender) SendContext(ctx context.Context, m message.Composer) error {
+	bl := s.Base.Level()
+	if bl.Valid() && !bl.ShouldLog(m) {
+		s.stats.recordFiltered()
+		return nil
+	}
+	start := time.Now()
+
+	if err := sendContextAll(ctx, s.senders, m); err != nil {
+		return err
+	}
+
+	s.recordSend(m, start)
+	return nil
+}
+
+// Flush flushes all of the member Senders in parallel.
+func (s *multiSender) Flush(ctx context.Context) error { return flushAll(ctx, s.senders) }
+
+func (s *multiSender) members() []Sender { return s.senders }
@@ -1,9 +1,11 @@
 package send
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
+	"time"
 
 	"github.com/mongodb/grip/level"
 	"github.com/mongodb/grip/message"
@@ -11,6 +13,7 @@ import (
 
 type nativeLogger struct {
 	logger *log.Logger
+	file   *logFile
 	*Base
 }
 
@@ -37,10 +40,11 @@ func MakeFileLogger(filePath string) (Sender, error) {
 		return nil, err
 	}
 
-	f, err := os.OpenFile(filePath, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0666)
+	f, err := openLogFile(filePath)
 	if err != nil {
-		return nil, fmt.Errorf("error opening logging file, %s", err.Error())
+		return nil, err
 	}
+	s.file = f
 
 	s.level = LevelInfo{level.Trace, level.Trace}
 
@@ -111,15 +115,38 @@ func NewErrorLogger(name string, l LevelInfo) (Sender, error) {
 	return setup(MakeErrorLogger(), name, l)
 }
 
+// Reopen reopens the file that the Sender writes to. Reopen does
+// nothing for Senders that do not write to files.
+func (s *nativeLogger) Reopen() error {
+	if s.file == nil {
+		return nil
+	}
+
+	return s.file.reopen()
+}
+
 func (s *nativeLogger) Send(m message.Composer) {
-	if s.level.ShouldLog(m) {
-		out, err := s.formatter(m)
+	if err := s.SendContext(context.Background(), m); err != nil {
+		s.errHandler(err, m)
+	}
+}
+
+func (s *nativeLogger) SendContext(ctx context.Context, m message.Composer) error {
+	if !s.shouldLog(m) {
+		return nil
+	}
+	start := time.Now()
 
-		if err != nil {
-			s.errHandler(err, m)
-			return
-		}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 
-		s.logger.Printf(out)
+	out, err := s.formatter(m)
+	if err != nil {
+		return err
 	}
+
+	s.logger.Print(out)
+	s.recordSend(m, start)
+	return nil
 }
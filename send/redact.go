@@ -0,0 +1,306 @@
+package send
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/mongodb/grip/level"
+	"github.com/mongodb/grip/message"
+)
+
+const defaultRedactionMask = "[REDACTED]"
+
+// Patterns for common kinds of secrets and personal information, for
+// use in RedactionOptions.
+var (
+	CreditCardPattern   = regexp.MustCompile(`\b(?:\d[ -]?){12,15}\d\b`)
+	BearerTokenPattern  = regexp.MustCompile(`(?i)\bbearer\s+[a-z0-9\-._~+/]+=*`)
+	AWSAccessKeyPattern = regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)
+	EmailPattern        = regexp.MustCompile(`[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}`)
+)
+
+// DefaultRedactionPatterns returns the patterns for credit card
+// numbers, bearer tokens, and AWS access keys.
+func DefaultRedactionPatterns() []*regexp.Regexp {
+	return []*regexp.Regexp{CreditCardPattern, BearerTokenPattern, AWSAccessKeyPattern}
+}
+
+// RedactionOptions configures the redacting Sender.
+type RedactionOptions struct {
+	// MaskKeys is a list of case-insensitive glob patterns (as
+	// in path.Match, e.g. "*password*"). The values of fields
+	// with matching keys are replaced with the Mask.
+	MaskKeys []string
+
+	// Patterns are applied to the rendered string form of
+	// messages and to string values in the raw form of
+	// messages; all matches are replaced with the Mask.
+	Patterns []*regexp.Regexp
+
+	// Mask replaces redacted content, and defaults to
+	// "[REDACTED]".
+	Mask string
+
+	// HashKeys is a list of case-insensitive glob patterns, and
+	// HashPatterns is a list of regular expressions. The values
+	// of matching fields and the text of matching substrings are
+	// replaced by an HMAC-SHA256 digest, computed with the
+	// HashSecret, so that they remain correlatable without
+	// revealing the original value. HashSecret must be set if
+	// either option is.
+	HashKeys     []string
+	HashPatterns []*regexp.Regexp
+	HashSecret   []byte
+}
+
+// Validate checks the options for invalid key patterns and missing
+// secrets, and sets the default mask if needed.
+func (o *RedactionOptions) Validate() error {
+	if o == nil {
+		return errors.New("redaction options cannot be nil")
+	}
+
+	errs := []string{}
+
+	for _, pattern := range append(append([]string{}, o.MaskKeys...), o.HashKeys...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			errs = append(errs, fmt.Sprintf("key pattern '%s' is not valid", pattern))
+		}
+	}
+
+	if (len(o.HashKeys) > 0 || len(o.HashPatterns) > 0) && len(o.HashSecret) == 0 {
+		errs = append(errs, "must specify a secret to hash values")
+	}
+
+	if o.Mask == "" {
+		o.Mask = defaultRedactionMask
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+func matchesKey(patterns []string, key string) bool {
+	key = strings.ToLower(key)
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(strings.ToLower(pattern), key); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (o *RedactionOptions) hash(value string) string {
+	mac := hmac.New(sha256.New, o.HashSecret)
+	_, _ = mac.Write([]byte(value))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (o *RedactionOptions) redactString(str string) string {
+	for _, pattern := range o.Patterns {
+		str = pattern.ReplaceAllLiteralString(str, o.Mask)
+	}
+
+	for _, pattern := range o.HashPatterns {
+		str = pattern.ReplaceAllStringFunc(str, o.hash)
+	}
+
+	return str
+}
+
+func (o *RedactionOptions) redactField(key string, value interface{}) interface{} {
+	if matchesKey(o.MaskKeys, key) {
+		return o.Mask
+	}
+
+	if matchesKey(o.HashKeys, key) {
+		return o.hash(fmt.Sprint(value))
+	}
+
+	return o.redactValue(value)
+}
+
+// redactValue returns a redacted copy of a value from the raw form of
+// a message. Types other than strings, maps, and slices are only
+// replaced, with their redacted string form, if their string form
+// contains matches.
+func (o *RedactionOptions) redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case string:
+		return o.redactString(v)
+	case message.Fields:
+		return o.redactMap(v)
+	case map[string]interface{}:
+		return map[string]interface{}(o.redactMap(message.Fields(v)))
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for idx := range v {
+			out[idx] = o.redactValue(v[idx])
+		}
+		return out
+	default:
+		str := fmt.Sprint(v)
+		if redacted := o.redactString(str); redacted != str {
+			return redacted
+		}
+		return v
+	}
+}
+
+func (o *RedactionOptions) redactMap(in message.Fields) message.Fields {
+	out := make(message.Fields, len(in))
+	for k, v := range in {
+		out[k] = o.redactField(k, v)
+	}
+
+	return out
+}
+
+type redactingSender struct {
+	senderWrapper
+	opts *RedactionOptions
+}
+
+// NewRedactingSender wraps an existing Sender, and scrubs secrets and
+// personal information from messages before sending them to the
+// wrapped Sender, as configured by the RedactionOptions.
+//
+// Because Composers render their content lazily, the redacting
+// Sender does not modify messages; instead it sends a new Composer
+// that wraps the original message and redacts its string and raw
+// forms when they are rendered. The raw form of a redacted message is
+// a message.Fields value if the original's was, and is otherwise the
+// JSON representation of the original's raw form, decoded into maps
+// and slices. The members of a message.GroupComposer are redacted
+// individually.
+func NewRedactingSender(sender Sender, opts *RedactionOptions) (Sender, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &redactingSender{senderWrapper: newSenderWrapper(sender), opts: opts}, nil
+}
+
+func (s *redactingSender) Send(m message.Composer) {
+	if !s.Level().ShouldLog(m) {
+		s.stats.recordFiltered()
+		return
+	}
+
+	start := time.Now()
+	s.Sender.Send(s.redact(m))
+	s.stats.recordSent(m.Priority(), time.Since(start))
+}
+
+func (s *redactingSender) SendContext(ctx context.Context, m message.Composer) error {
+	if !s.Level().ShouldLog(m) {
+		s.stats.recordFiltered()
+		return nil
+	}
+
+	start := time.Now()
+	if err := SendContext(ctx, s.Sender, s.redact(m)); err != nil {
+		return err
+	}
+
+	s.stats.recordSent(m.Priority(), time.Since(start))
+	return nil
+}
+
+func (s *redactingSender) redact(m message.Composer) message.Composer {
+	if group, ok := m.(*message.GroupComposer); ok {
+		msgs := group.Messages()
+		out := make([]message.Composer, len(msgs))
+		for idx := range msgs {
+			out[idx] = s.redact(msgs[idx])
+		}
+
+		return message.NewGroupComposer(out)
+	}
+
+	return &redactedMessage{original: m, opts: s.opts}
+}
+
+type redactedMessage struct {
+	original message.Composer
+	opts     *RedactionOptions
+	rendered bool
+	str      string
+	raw      interface{}
+}
+
+func (m *redactedMessage) Loggable() bool                     { return m.original.Loggable() }
+func (m *redactedMessage) Priority() level.Priority           { return m.original.Priority() }
+func (m *redactedMessage) SetPriority(p level.Priority) error { return m.original.SetPriority(p) }
+
+func (m *redactedMessage) SetCallSite(frame message.StackFrame) {
+	if c, ok := m.original.(message.CallSiteComposer); ok {
+		c.SetCallSite(frame)
+	}
+}
+
+func (m *redactedMessage) GetCallSite() (message.StackFrame, bool) {
+	return message.GetCallSite(m.original)
+}
+
+func (m *redactedMessage) String() string {
+	m.render()
+	return m.str
+}
+
+func (m *redactedMessage) Raw() interface{} {
+	m.render()
+	return m.raw
+}
+
+func (m *redactedMessage) render() {
+	if m.rendered {
+		return
+	}
+	m.rendered = true
+
+	raw := m.original.Raw()
+
+	if fields, ok := raw.(message.Fields); ok {
+		redacted := m.opts.redactMap(fields)
+		m.raw = redacted
+
+		// rebuild the string form from the redacted fields, so
+		// that masked and hashed values do not appear in it.
+		msg, _ := redacted["msg"].(string)
+		m.str = message.MakeFieldsMessage(msg, redacted).String()
+		return
+	}
+
+	m.str = m.opts.redactString(m.original.String())
+
+	out, err := json.Marshal(raw)
+	if err != nil {
+		m.raw = m.str
+		return
+	}
+
+	var decoded interface{}
+	if err := json.Unmarshal(out, &decoded); err != nil {
+		m.raw = m.str
+		return
+	}
+
+	m.raw = m.opts.redactValue(decoded)
+}
@@ -0,0 +1,154 @@
+package send
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Reopener is implemented by Senders that write to files. Reopen
+// closes and reopens the file by its path, which allows an external
+// tool (e.g. logrotate) to rotate the log files by renaming them, and
+// then ask the process to reopen them (typically with SIGHUP.)
+type Reopener interface {
+	Reopen() error
+}
+
+// Reopen is a helper function that reopens the files of all of the
+// Senders in a tree of Senders (see Members) that implement Reopener,
+// and returns an error that combines the errors from all of them.
+func Reopen(s Sender) error {
+	errs := []string{}
+
+	if r, ok := s.(Reopener); ok {
+		if err := r.Reopen(); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	for _, member := range Members(s) {
+		if err := Reopen(member); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "\n"))
+	}
+
+	return nil
+}
+
+// logFile is an io.Writer for a log file that file Senders can reopen
+// while other goroutines are writing to it. If maxSize is set, the
+// file is rotated before a write would make it larger than maxSize:
+// the file is renamed with the suffix ".1", previously rotated files
+// are renamed with the next suffix, and only the newest keep rotated
+// files are retained.
+type logFile struct {
+	path    string
+	file    *os.File
+	size    int64
+	maxSize int64
+	keep    int
+	mutex   sync.Mutex
+}
+
+func openLogFile(path string) (*logFile, error) {
+	f, size, err := openLogFileAppend(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening logging file, %s", err.Error())
+	}
+
+	return &logFile{path: path, file: f, size: size}, nil
+}
+
+func openLogFileAppend(path string) (*os.File, int64, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0666)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, 0, err
+	}
+
+	return f, info.Size(), nil
+}
+
+func (f *logFile) Write(p []byte) (int, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.maxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.maxSize {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+
+	return n, err
+}
+
+// rotate renames the file and the previously rotated files, and opens
+// a new file. The caller must hold the mutex.
+func (f *logFile) rotate() error {
+	keep := f.keep
+	if keep < 1 {
+		keep = 1
+	}
+
+	if err := os.Remove(fmt.Sprintf("%s.%d", f.path, keep)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error rotating logging file, %s", err.Error())
+	}
+
+	for idx := keep - 1; idx > 0; idx-- {
+		err := os.Rename(fmt.Sprintf("%s.%d", f.path, idx), fmt.Sprintf("%s.%d", f.path, idx+1))
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("error rotating logging file, %s", err.Error())
+		}
+	}
+
+	if err := os.Rename(f.path, f.path+".1"); err != nil {
+		return fmt.Errorf("error rotating logging file, %s", err.Error())
+	}
+
+	next, size, err := openLogFileAppend(f.path)
+	if err != nil {
+		return fmt.Errorf("error rotating logging file, %s", err.Error())
+	}
+
+	prev := f.file
+	f.file = next
+	f.size = size
+
+	return prev.Close()
+}
+
+func (f *logFile) reopen() error {
+	next, size, err := openLogFileAppend(f.path)
+	if err != nil {
+		return fmt.Errorf("error reopening logging file, %s", err.Error())
+	}
+
+	f.mutex.Lock()
+	prev := f.file
+	f.file = next
+	f.size = size
+	f.mutex.Unlock()
+
+	return prev.Close()
+}
+
+func (f *logFile) Close() error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return f.file.Close()
+}
@@ -0,0 +1,227 @@
+package send
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mongodb/grip/level"
+	"github.com/mongodb/grip/message"
+)
+
+const defaultRingMessages = 1000
+
+// RingOptions configures the limits of a RingSender. If neither limit
+// is set, the Sender keeps 1000 messages.
+type RingOptions struct {
+	// Messages is the maximum number of messages to keep.
+	Messages int
+
+	// Bytes is the maximum total size of the rendered form of the
+	// messages to keep.
+	Bytes int
+}
+
+// RingEntry is a message that a RingSender received. The rendered and
+// Raw forms of the message are captured when the message is sent.
+type RingEntry struct {
+	Time     time.Time        `json:"time"`
+	Priority level.Priority   `json:"priority"`
+	Rendered string           `json:"message"`
+	Raw      interface{}      `json:"raw"`
+	Message  message.Composer `json:"-"`
+}
+
+// RingQuery selects messages from a RingSender. Zero values match all
+// messages.
+type RingQuery struct {
+	// Since and Until select messages received in a time range;
+	// both ends of the range are inclusive.
+	Since time.Time
+	Until time.Time
+
+	// MinPriority selects messages at or above the priority.
+	MinPriority level.Priority
+
+	// Fields selects messages whose Raw form is a message.Fields
+	// value that contains all of the key/value pairs.
+	Fields message.Fields
+
+	// Contains selects messages whose rendered form contains the
+	// string.
+	Contains string
+
+	// Limit, if set, selects only the most recent matching
+	// messages.
+	Limit int
+}
+
+// RingSender is a Sender that keeps the most recent messages in memory,
+// to support pages that show recent errors, or to include the messages
+// that preceded a crash in a report. Sending a message never blocks
+// (beyond a brief lock to store it), and the oldest messages are
+// discarded once the Sender reaches its limits.
+type RingSender struct {
+	entries []RingEntry
+	head    int
+	bytes   int
+	opts    RingOptions
+	lock    sync.RWMutex
+	*Base
+}
+
+// NewRingSender constructs a RingSender with the specified name,
+// levels, and limits.
+func NewRingSender(name string, l LevelInfo, opts RingOptions) (*RingSender, error) {
+	if opts.Messages <= 0 && opts.Bytes <= 0 {
+		opts.Messages = defaultRingMessages
+	}
+
+	s := &RingSender{opts: opts, Base: NewBase(name)}
+	if opts.Messages > 0 {
+		s.entries = make([]RingEntry, 0, opts.Messages)
+	}
+
+	if err := s.SetLevel(l); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// MakeRingSender constructs a RingSender that keeps the specified
+// number of messages of all priorities.
+func MakeRingSender(size int) *RingSender {
+	s, _ := NewRingSender("", LevelInfo{level.Trace, level.Trace}, RingOptions{Messages: size})
+	return s
+}
+
+func (s *RingSender) Send(m message.Composer) {
+	if !s.shouldLog(m) {
+		return
+	}
+	defer s.recordSend(m, time.Now())
+
+	// render the message before taking the lock.
+	entry := RingEntry{
+		Time:     time.Now(),
+		Priority: m.Priority(),
+		Rendered: m.String(),
+		Raw:      m.Raw(),
+		Message:  m,
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.entries = append(s.entries, entry)
+	s.bytes += len(entry.Rendered)
+
+	for s.len() > 0 && ((s.opts.Messages > 0 && s.len() > s.opts.Messages) || (s.opts.Bytes > 0 && s.bytes > s.opts.Bytes)) {
+		s.bytes -= len(s.entries[s.head].Rendered)
+		s.entries[s.head] = RingEntry{}
+		s.head++
+	}
+
+	// reclaim the space of discarded messages once they make up
+	// half of the slice, so that appending is amortized O(1).
+	if s.head > 0 && s.head >= len(s.entries)/2 {
+		n := copy(s.entries, s.entries[s.head:])
+		for idx := n; idx < len(s.entries); idx++ {
+			s.entries[idx] = RingEntry{}
+		}
+		s.entries = s.entries[:n]
+		s.head = 0
+	}
+}
+
+// len returns the number of messages; the caller must hold the lock.
+func (s *RingSender) len() int { return len(s.entries) - s.head }
+
+// Len returns the number of messages that the Sender holds.
+func (s *RingSender) Len() int {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.len()
+}
+
+// Bytes returns the total size of the rendered form of the messages
+// that the Sender holds.
+func (s *RingSender) Bytes() int {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.bytes
+}
+
+// Reset discards all of the messages.
+func (s *RingSender) Reset() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.entries = s.entries[:0]
+	s.head = 0
+	s.bytes = 0
+}
+
+// Query returns the messages that match the query, oldest first.
+func (s *RingSender) Query(q RingQuery) []RingEntry {
+	// copy the entries, so that filtering does not hold the lock.
+	s.lock.RLock()
+	entries := make([]RingEntry, s.len())
+	copy(entries, s.entries[s.head:])
+	s.lock.RUnlock()
+
+	out := []RingEntry{}
+	for idx := len(entries) - 1; idx >= 0; idx-- {
+		if q.Limit > 0 && len(out) == q.Limit {
+			break
+		}
+
+		if q.matches(entries[idx]) {
+			out = append(out, entries[idx])
+		}
+	}
+
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+
+	return out
+}
+
+func (q RingQuery) matches(e RingEntry) bool {
+	if !q.Since.IsZero() && e.Time.Before(q.Since) {
+		return false
+	}
+
+	if !q.Until.IsZero() && e.Time.After(q.Until) {
+		return false
+	}
+
+	if e.Priority < q.MinPriority {
+		return false
+	}
+
+	if q.Contains != "" && !strings.Contains(e.Rendered, q.Contains) {
+		return false
+	}
+
+	if len(q.Fields) > 0 {
+		fields, ok := e.Raw.(message.Fields)
+		if !ok {
+			return false
+		}
+
+		for k, v := range q.Fields {
+			actual, ok := fields[k]
+			if !ok || !reflect.DeepEqual(v, actual) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,146 @@
+package send
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mongodb/grip/level"
+	"github.com/mongodb/grip/message"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRingSenderKeepsRecentMessages(t *testing.T) {
+	assert := assert.New(t)
+
+	s := MakeRingSender(3)
+	assert.Len(s.Query(RingQuery{}), 0)
+
+	for _, msg := range []string{"one", "two", "three", "four", "five"} {
+		s.Send(message.NewDefaultMessage(level.Info, msg))
+	}
+	s.Send(message.NewDefaultMessage(level.Info, ""))
+
+	assert.Equal(3, s.Len())
+	assert.Equal(len("threefourfive"), s.Bytes())
+
+	entries := s.Query(RingQuery{})
+	if assert.Len(entries, 3) {
+		assert.Equal("three", entries[0].Rendered)
+		assert.Equal("five", entries[2].Rendered)
+	}
+
+	entries = s.Query(RingQuery{Limit: 2})
+	if assert.Len(entries, 2) {
+		assert.Equal("four", entries[0].Rendered)
+	}
+
+	s.Reset()
+	assert.Equal(0, s.Len())
+	assert.Equal(0, s.Bytes())
+}
+
+func TestRingSenderByteLimit(t *testing.T) {
+	assert := assert.New(t)
+
+	s, err := NewRingSender("ring", LevelInfo{level.Info, level.Info}, RingOptions{Bytes: 100})
+	assert.NoError(err)
+
+	for i := 0; i < 1000; i++ {
+		s.Send(message.NewDefaultMessage(level.Info, strings.Repeat("x", 10)))
+	}
+	assert.Equal(10, s.Len())
+	assert.Equal(100, s.Bytes())
+
+	// messages larger than the limit are not kept
+	s.Send(message.NewDefaultMessage(level.Info, strings.Repeat("x", 101)))
+	assert.Equal(0, s.Len())
+
+	_, err = NewRingSender("ring", LevelInfo{level.Invalid, level.Info}, RingOptions{})
+	assert.Error(err)
+}
+
+func TestRingSenderQuery(t *testing.T) {
+	assert := assert.New(t)
+
+	s := MakeRingSender(100)
+	s.Send(message.NewDefaultMessage(level.Info, "starting up"))
+	s.Send(message.NewFieldsMessage(level.Error, "request failed", message.Fields{"user": "alice", "code": 500}))
+	s.Send(message.NewFieldsMessage(level.Warning, "slow request", message.Fields{"user": "alice"}))
+
+	start := time.Now()
+	s.Send(message.NewFieldsMessage(level.Error, "request failed", message.Fields{"user": "bob", "code": 503}))
+	s.Send(message.NewDefaultMessage(level.Debug, "shutting down"))
+
+	for name, test := range map[string]struct {
+		query    RingQuery
+		expected []string
+	}{
+		"Priority": {
+			query:    RingQuery{MinPriority: level.Warning},
+			expected: []string{"request failed", "slow request", "request failed"},
+		},
+		"Fields": {
+			query:    RingQuery{Fields: message.Fields{"user": "alice"}},
+			expected: []string{"request failed", "slow request"},
+		},
+		"FieldsAndPriority": {
+			query:    RingQuery{Fields: message.Fields{"user": "alice"}, MinPriority: level.Error},
+			expected: []string{"request failed"},
+		},
+		"Substring": {
+			query:    RingQuery{Contains: "ing"},
+			expected: []string{"starting up", "shutting down"},
+		},
+		"Since": {
+			query:    RingQuery{Since: start},
+			expected: []string{"request failed", "shutting down"},
+		},
+		"Until": {
+			query:    RingQuery{Until: start.Add(-time.Nanosecond), MinPriority: level.Error},
+			expected: []string{"request failed"},
+		},
+		"LimitAndPriority": {
+			query:    RingQuery{MinPriority: level.Error, Limit: 1},
+			expected: []string{"request failed"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			entries := s.Query(test.query)
+			if assert.Len(entries, len(test.expected)) {
+				for idx := range entries {
+					assert.Contains(entries[idx].Rendered, test.expected[idx])
+				}
+			}
+		})
+	}
+
+	entries := s.Query(RingQuery{Limit: 1, MinPriority: level.Error})
+	assert.Equal("bob", entries[0].Message.Raw().(message.Fields)["user"])
+}
+
+func TestRingSenderConcurrentUse(t *testing.T) {
+	assert := assert.New(t)
+
+	s := MakeRingSender(50)
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				s.Send(message.NewDefaultMessage(level.Info, "concurrent"))
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				assert.True(len(s.Query(RingQuery{Contains: "concurrent"})) <= 50)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(50, s.Len())
+}
@@ -0,0 +1,405 @@
+package send
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mongodb/grip/level"
+	"github.com/mongodb/grip/message"
+)
+
+// Route describes a rule for the router Sender. A message matches a
+// route if it matches all of the criteria that the route specifies;
+// a route with no criteria matches every message. Messages that match
+// are sent to all of the route's Senders.
+type Route struct {
+	// MinPriority and MaxPriority define an inclusive range of
+	// priorities. A value of level.Invalid (e.g. 0) leaves that
+	// end of the range unbounded.
+	MinPriority level.Priority
+	MaxPriority level.Priority
+
+	// Names is a list of glob patterns, as in path.Match, and
+	// matches if any of the patterns match the logger name. The
+	// logger name is the value of the "logger" key for messages
+	// whose Raw form is a message.Fields value, and is otherwise
+	// the name of the router itself.
+	Names []string
+
+	// Fields matches messages whose Raw form is a message.Fields
+	// value and contains all of the specified key/value pairs.
+	// Numbers match numbers of any type with the same value, so
+	// that numbers decoded from configuration files (e.g. float64
+	// from JSON) match integer fields.
+	Fields message.Fields
+
+	// Types matches if the type name of the message (as printed
+	// by fmt's %T verb, e.g. "*message.GroupComposer") is in the
+	// list.
+	Types []string
+
+	// Match is an optional predicate for any criteria not
+	// covered by the other options.
+	Match func(message.Composer) bool
+
+	// Senders receive the messages that match this route.
+	Senders []Sender
+
+	// By default, the router stops at the first matching route;
+	// if Continue is true, the router continues to check the
+	// remaining routes.
+	Continue bool
+}
+
+// Validate returns an error if the route has no Senders, an invalid
+// priority bound, or an invalid name pattern.
+func (r Route) Validate() error {
+	errs := []string{}
+
+	if len(r.Senders) == 0 {
+		errs = append(errs, "route must have at least one sender")
+	}
+
+	if r.MinPriority != level.Invalid && !level.IsValidPriority(r.MinPriority) {
+		errs = append(errs, fmt.Sprintf("minimum priority %d is not valid", r.MinPriority))
+	}
+
+	if r.MaxPriority != level.Invalid && !level.IsValidPriority(r.MaxPriority) {
+		errs = append(errs, fmt.Sprintf("maximum priority %d is not valid", r.MaxPriority))
+	}
+
+	for _, pattern := range r.Names {
+		if _, err := path.Match(pattern, ""); err != nil {
+			errs = append(errs, fmt.Sprintf("name pattern '%s' is not valid", pattern))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+func (r Route) matches(name string, m message.Composer) bool {
+	p := m.Priority()
+	if r.MinPriority != level.Invalid && p < r.MinPriority {
+		return false
+	}
+
+	if r.MaxPriority != level.Invalid && p > r.MaxPriority {
+		return false
+	}
+
+	if len(r.Types) > 0 && !r.matchesType(m) {
+		return false
+	}
+
+	if len(r.Names) > 0 || len(r.Fields) > 0 {
+		fields, _ := m.Raw().(message.Fields)
+
+		if len(r.Names) > 0 {
+			if logger, ok := fields["logger"].(string); ok {
+				name = logger
+			}
+
+			if !r.matchesName(name) {
+				return false
+			}
+		}
+
+		for k, v := range r.Fields {
+			actual, ok := fields[k]
+			if !ok || !routeValuesEqual(v, actual) {
+				return false
+			}
+		}
+	}
+
+	if r.Match != nil && !r.Match(m) {
+		return false
+	}
+
+	return true
+}
+
+// routeValuesEqual compares route field values, treating numbers of
+// different types with the same value as equal.
+func routeValuesEqual(expected, actual interface{}) bool {
+	if reflect.DeepEqual(expected, actual) {
+		return true
+	}
+
+	e, ok := routeNumber(expected)
+	if !ok {
+		return false
+	}
+
+	a, ok := routeNumber(actual)
+
+	return ok && e == a
+}
+
+func routeNumber(v interface{}) (float64, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	default:
+		return 0, false
+	}
+}
+
+func (r Route) matchesName(name string) bool {
+	for _, pattern := range r.Names {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r Route) matchesType(m message.Composer) bool {
+	name := fmt.Sprintf("%T", m)
+	for _, t := range r.Types {
+		if t == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+type routerSender struct {
+	routes   []Route
+	fallback []Sender
+	rmutex   sync.RWMutex
+	*Base
+}
+
+// NewRouterSender constructs a Sender that dispatches messages to
+// other Senders based on an ordered list of routes. For each message
+// that passes the router's own level configuration, the router checks
+// the routes in order and sends the message to the Senders of every
+// matching route until it reaches a matching route that does not set
+// Continue. Messages that match no route go to the default Senders,
+// if any.
+//
+// Unlike the multi sender, the router does not modify the name or
+// level configuration of its member Senders, which must be fully
+// configured before constructing the router. Use SetRoutes to replace
+// the routes while the router is in use.
+func NewRouterSender(name string, l LevelInfo, defaults []Sender, routes ...Route) (Sender, error) {
+	s, err := MakeRouterSender(defaults, routes...)
+	if err != nil {
+		return nil, err
+	}
+
+	return setup(s, name, l)
+}
+
+// MakeRouterSender constructs an unconfigured router Sender. See
+// NewRouterSender for more information.
+func MakeRouterSender(defaults []Sender, routes ...Route) (Sender, error) {
+	s := &routerSender{Base: NewBase("")}
+
+	s.level = LevelInfo{level.Trace, level.Trace}
+
+	if err := s.setRoutes(defaults, routes); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// SetRoutes is a helper function that replaces the routes and default
+// Senders of a router Sender. Messages that the router is sending
+// when SetRoutes is called are delivered according to the previous
+// routes, and all subsequent messages use the new routes. SetRoutes
+// does not close the Senders of the previous routes.
+//
+// Returns an error if the Sender is not a router Sender or if any of
+// the routes are invalid, in which case the existing routes remain in
+// effect.
+func SetRoutes(router Sender, defaults []Sender, routes ...Route) error {
+	sender, ok := router.(*routerSender)
+	if !ok {
+		return fmt.Errorf("%s is not a router sender", router.Name())
+	}
+
+	return sender.setRoutes(defaults, routes)
+}
+
+func (s *routerSender) setRoutes(defaults []Sender, routes []Route) error {
+	errs := []string{}
+	for idx, r := range routes {
+		if err := r.Validate(); err != nil {
+			errs = append(errs, fmt.Sprintf("route %d: %s", idx, err.Error()))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "\n"))
+	}
+
+	s.rmutex.Lock()
+	defer s.rmutex.Unlock()
+
+	s.routes = routes
+	s.fallback = defaults
+
+	return nil
+}
+
+func (s *routerSender) Send(m message.Composer) {
+	if !s.Level().ShouldLog(m) {
+		s.stats.recordFiltered()
+		return
+	}
+
+	start := time.Now()
+	targets := s.targets(m)
+	if len(targets) == 0 {
+		s.stats.recordDropped()
+		return
+	}
+
+	for _, sender := range targets {
+		sender.Send(m)
+	}
+
+	s.stats.recordSent(m.Priority(), time.Since(start))
+}
+
+// SendContext sends the message to the Senders of the matching
+// routes, and returns an error that combines the errors from all of
+// these Senders. Messages that match no route, when the router has no
+// default Senders, are dropped without an error.
+func (s *routerSender) SendContext(ctx context.Context, m message.Composer) error {
+	if !s.Level().ShouldLog(m) {
+		s.stats.recordFiltered()
+		return nil
+	}
+
+	start := time.Now()
+	targets := s.targets(m)
+	if len(targets) == 0 {
+		s.stats.recordDropped()
+		return nil
+	}
+
+	if err := sendContextAll(ctx, targets, m); err != nil {
+		return err
+	}
+
+	s.stats.recordSent(m.Priority(), time.Since(start))
+	return nil
+}
+
+// targets returns the Senders of the routes that match the message,
+// listing each Sender once, or the default Senders if no route
+// matches.
+func (s *routerSender) targets(m message.Composer) []Sender {
+	name := s.Name()
+
+	s.rmutex.RLock()
+	defer s.rmutex.RUnlock()
+
+	var out []Sender
+	seen := map[Sender]struct{}{}
+	matched := false
+	for _, r := range s.routes {
+		if !r.matches(name, m) {
+			continue
+		}
+
+		matched = true
+		for _, sender := range r.Senders {
+			if _, ok := seen[sender]; ok {
+				continue
+			}
+			seen[sender] = struct{}{}
+			out = append(out, sender)
+		}
+
+		if !r.Continue {
+			break
+		}
+	}
+
+	if !matched {
+		return s.fallback
+	}
+
+	return out
+}
+
+// members returns all of the Senders in the current routes and
+// defaults, listing each Sender once.
+func (s *routerSender) members() []Sender {
+	s.rmutex.RLock()
+	defer s.rmutex.RUnlock()
+
+	seen := map[Sender]struct{}{}
+	out := []Sender{}
+	add := func(senders []Sender) {
+		for _, sender := range senders {
+			if _, ok := seen[sender]; ok {
+				continue
+			}
+			seen[sender] = struct{}{}
+			out = append(out, sender)
+		}
+	}
+
+	for _, r := range s.routes {
+		add(r.Senders)
+	}
+	add(s.fallback)
+
+	return out
+}
+
+// Close closes all of the Senders in the current routes and defaults,
+// closing each Sender once even if it appears in more than one route.
+func (s *routerSender) Close() error {
+	s.rmutex.RLock()
+	defer s.rmutex.RUnlock()
+
+	seen := map[Sender]struct{}{}
+	errs := []string{}
+	closeSenders := func(senders []Sender) {
+		for _, sender := range senders {
+			if _, ok := seen[sender]; ok {
+				continue
+			}
+			seen[sender] = struct{}{}
+
+			if err := sender.Close(); err != nil {
+				errs = append(errs, err.Error())
+			}
+		}
+	}
+
+	for _, r := range s.routes {
+		closeSenders(r.Senders)
+	}
+	closeSenders(s.fallback)
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "\n"))
+	}
+
+	return nil
+}
@@ -0,0 +1,120 @@
+package send
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mongodb/grip/level"
+	"github.com/mongodb/grip/message"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func makeRouterSinks(t *testing.T, names ...string) map[string]*InternalSender {
+	out := map[string]*InternalSender{}
+	for _, n := range names {
+		sink, err := NewInternalLogger(n, LevelInfo{level.Trace, level.Trace})
+		require.NoError(t, err)
+		out[n] = sink
+	}
+
+	return out
+}
+
+func TestRouterSenderDispatch(t *testing.T) {
+	assert := assert.New(t)
+	sinks := makeRouterSinks(t, "pager", "file", "audit", "default")
+
+	router, err := NewRouterSender("router", LevelInfo{level.Info, level.Debug},
+		[]Sender{sinks["default"]},
+		Route{Fields: message.Fields{"audit": true}, Senders: []Sender{sinks["audit"]}},
+		Route{MinPriority: level.Error, Senders: []Sender{sinks["pager"]}, Continue: true},
+		Route{MinPriority: level.Info, Senders: []Sender{sinks["file"]}})
+	require.NoError(t, err)
+
+	router.Send(message.NewDefaultMessage(level.Trace, "below threshold"))
+	router.Send(message.NewDefaultMessage(level.Critical, "page me"))
+	router.Send(message.NewDefaultMessage(level.Info, "informational"))
+	router.Send(message.NewFields(level.Critical, message.Fields{"audit": true, "user": "a"}))
+	router.Send(message.NewDefaultMessage(level.Debug, "unrouted"))
+
+	assert.Equal(1, sinks["pager"].Len())
+	assert.Equal("page me", sinks["pager"].GetMessage().Rendered)
+	assert.Equal(2, sinks["file"].Len())
+	assert.Equal(1, sinks["audit"].Len())
+	assert.Equal(1, sinks["default"].Len())
+	assert.Equal("unrouted", sinks["default"].GetMessage().Rendered)
+}
+
+func TestRouterSenderSendsOnceToSharedSenders(t *testing.T) {
+	assert := assert.New(t)
+	sinks := makeRouterSinks(t, "file", "pager")
+
+	router, err := NewRouterSender("router", LevelInfo{level.Info, level.Debug}, nil,
+		Route{MinPriority: level.Error, Senders: []Sender{sinks["file"], sinks["pager"]}, Continue: true},
+		Route{MinPriority: level.Info, Senders: []Sender{sinks["file"]}})
+	require.NoError(t, err)
+
+	router.Send(message.NewDefaultMessage(level.Error, "once"))
+	assert.NoError(SendContext(context.Background(), router, message.NewDefaultMessage(level.Error, "again")))
+	router.Send(message.NewDefaultMessage(level.Info, "info"))
+
+	assert.Equal(3, sinks["file"].Len())
+	assert.Equal(2, sinks["pager"].Len())
+}
+
+func TestRouterSenderNamesAndTypes(t *testing.T) {
+	assert := assert.New(t)
+	sinks := makeRouterSinks(t, "db", "group", "custom")
+
+	router, err := MakeRouterSender(nil,
+		Route{Names: []string{"db.*"}, Senders: []Sender{sinks["db"]}},
+		Route{Types: []string{"*message.GroupComposer"}, Senders: []Sender{sinks["group"]}},
+		Route{
+			Match:   func(m message.Composer) bool { return m.String() == "custom" },
+			Senders: []Sender{sinks["custom"]},
+		})
+	require.NoError(t, err)
+	router.SetName("app")
+
+	router.Send(message.NewFields(level.Info, message.Fields{"logger": "db.pool"}))
+	router.Send(message.NewFields(level.Info, message.Fields{"logger": "web"}))
+	router.Send(message.MakeGroupComposer(
+		message.NewDefaultMessage(level.Info, "one"),
+		message.NewDefaultMessage(level.Info, "two")))
+	router.Send(message.NewDefaultMessage(level.Info, "custom"))
+
+	assert.Equal(1, sinks["db"].Len())
+	assert.Equal(1, sinks["group"].Len())
+	assert.Equal(1, sinks["custom"].Len())
+
+	router.SetName("db.main")
+	router.Send(message.NewDefaultMessage(level.Info, "named by router"))
+	assert.Equal(2, sinks["db"].Len())
+}
+
+func TestRouterSenderSetRoutes(t *testing.T) {
+	assert := assert.New(t)
+	sinks := makeRouterSinks(t, "one", "two")
+
+	router, err := MakeRouterSender([]Sender{sinks["one"]})
+	require.NoError(t, err)
+	router.Send(message.NewDefaultMessage(level.Info, "hello"))
+	assert.Equal(1, sinks["one"].Len())
+
+	assert.Error(SetRoutes(router, nil, Route{}))
+	assert.Error(SetRoutes(router, nil, Route{Senders: []Sender{sinks["two"]}, Names: []string{"["}}))
+	assert.Error(SetRoutes(router, nil, Route{Senders: []Sender{sinks["two"]}, MinPriority: 1000}))
+	assert.Error(SetRoutes(sinks["one"], nil))
+
+	router.Send(message.NewDefaultMessage(level.Info, "hello"))
+	assert.Equal(2, sinks["one"].Len())
+
+	assert.NoError(SetRoutes(router, nil, Route{Senders: []Sender{sinks["two"]}}))
+	router.Send(message.NewDefaultMessage(level.Info, "hello"))
+	assert.Equal(2, sinks["one"].Len())
+	assert.Equal(1, sinks["two"].Len())
+
+	_, err = NewRouterSender("router", LevelInfo{}, nil)
+	assert.Error(err)
+}
@@ -0,0 +1,122 @@
+package send
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mongodb/grip/message"
+	"github.com/mongodb/grip/sometimes"
+)
+
+// Sampler is a function that decides whether a message should be
+// sent. Use the Sample* constructors to build Samplers from the
+// predicates in the sometimes package.
+type Sampler func(message.Composer) bool
+
+type samplingSender struct {
+	senderWrapper
+	samplers []Sampler
+}
+
+// NewSamplingSender wraps an existing Sender and only sends messages
+// that all of the samplers accept. Messages that would not be logged
+// according to the Sender's level configuration are dropped before
+// they reach the samplers, so they do not affect the sampling
+// decisions.
+func NewSamplingSender(sender Sender, samplers ...Sampler) Sender {
+	return &samplingSender{
+		senderWrapper: newSenderWrapper(sender),
+		samplers:      samplers,
+	}
+}
+
+func (s *samplingSender) Send(m message.Composer) {
+	if !s.Level().ShouldLog(m) {
+		s.stats.recordFiltered()
+		return
+	}
+
+	for _, sample := range s.samplers {
+		if !sample(m) {
+			s.stats.recordDropped()
+			return
+		}
+	}
+
+	start := time.Now()
+	s.Sender.Send(m)
+	s.stats.recordSent(m.Priority(), time.Since(start))
+}
+
+// SendContext sends the message to the wrapped Sender if the samplers
+// accept it. Messages that the samplers reject are not errors.
+func (s *samplingSender) SendContext(ctx context.Context, m message.Composer) error {
+	if !s.Level().ShouldLog(m) {
+		s.stats.recordFiltered()
+		return nil
+	}
+
+	for _, sample := range s.samplers {
+		if !sample(m) {
+			s.stats.recordDropped()
+			return nil
+		}
+	}
+
+	start := time.Now()
+	if err := SendContext(ctx, s.Sender, m); err != nil {
+		return err
+	}
+
+	s.stats.recordSent(m.Priority(), time.Since(start))
+	return nil
+}
+
+// SampleRate returns a Sampler that accepts the specified fraction
+// (between 0 and 1) of messages using the random source. If the source
+// is nil, the Sampler uses the sometimes package's default source.
+func SampleRate(source *sometimes.Source, r float64) Sampler {
+	if source == nil {
+		return func(_ message.Composer) bool { return sometimes.Rate(r) }
+	}
+
+	return func(_ message.Composer) bool { return source.Rate(r) }
+}
+
+// SampleByKey returns a Sampler that deterministically accepts the
+// specified fraction of messages based on the value of a field in
+// messages whose Raw form is a message.Fields value. All messages
+// with the same value for the field are either kept or dropped
+// together. Messages without the field are always accepted.
+func SampleByKey(field string, r float64) Sampler {
+	return func(m message.Composer) bool {
+		fields, ok := m.Raw().(message.Fields)
+		if !ok {
+			return true
+		}
+
+		v, ok := fields[field]
+		if !ok {
+			return true
+		}
+
+		return sometimes.Key(fmt.Sprint(v), r)
+	}
+}
+
+// SampleFirstThenEvery returns a Sampler that accepts the first
+// messages it sees, and then every Mth message.
+func SampleFirstThenEvery(first, every int) Sampler {
+	c := sometimes.NewCounter(first, every)
+
+	return func(_ message.Composer) bool { return c.Check() }
+}
+
+// SampleLimit returns a Sampler that accepts no more than perSecond
+// messages every second.
+func SampleLimit(perSecond int) Sampler {
+	l := sometimes.NewLimiter(perSecond)
+
+	return func(_ message.Composer) bool { return l.Check() }
+}
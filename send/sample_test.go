@@ -0,0 +1,46 @@
+package send
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/mongodb/grip/level"
+	"github.com/mongodb/grip/message"
+	"github.com/mongodb/grip/sometimes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSamplingSender(t *testing.T) {
+	assert := assert.New(t)
+
+	sink, err := NewInternalLogger("sample", LevelInfo{level.Info, level.Info})
+	assert.NoError(err)
+
+	s := NewSamplingSender(sink, SampleFirstThenEvery(2, 10))
+	s.Send(message.NewDefaultMessage(level.Debug, "below threshold"))
+	for i := 0; i < 22; i++ {
+		s.Send(message.NewDefaultMessage(level.Info, "hello"))
+	}
+	assert.Equal(4, sink.Len())
+
+	sink, err = NewInternalLogger("sample", LevelInfo{level.Info, level.Info})
+	assert.NoError(err)
+	s = NewSamplingSender(sink, SampleRate(sometimes.NewSource(1), 0), SampleLimit(100))
+	s.Send(message.NewDefaultMessage(level.Info, "hello"))
+	assert.Equal(0, sink.Len())
+}
+
+func TestSampleByKeyKeepsRequestsTogether(t *testing.T) {
+	assert := assert.New(t)
+
+	sample := SampleByKey("request", 0.5)
+	for i := 0; i < 100; i++ {
+		id := fmt.Sprintf("req-%d", i)
+		first := sample(message.NewFields(level.Info, message.Fields{"request": id, "step": 1}))
+		second := sample(message.NewFields(level.Info, message.Fields{"request": id, "step": 2}))
+		assert.Equal(first, second)
+	}
+
+	assert.True(sample(message.NewDefaultMessage(level.Info, "no fields")))
+	assert.True(sample(message.NewFields(level.Info, message.Fields{"other": 1})))
+}
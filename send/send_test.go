@@ -116,6 +116,16 @@ func (s *SenderSuite) SetupTest() {
 	bufferedInternal, err := NewNativeLogger("buffered", l)
 	s.Require().NoError(err)
 	s.senders["buffered"] = NewBufferedSender(bufferedInternal, minBufferLength, 1)
+
+	dedupInternal, err := NewNativeLogger("dedup", l)
+	s.Require().NoError(err)
+	s.senders["dedup"] = NewDedupSender(dedupInternal, time.Minute)
+
+	routed, err := NewNativeLogger("routed", l)
+	s.Require().NoError(err)
+	router, err := NewRouterSender("router", l, []Sender{routed})
+	s.Require().NoError(err)
+	s.senders["router"] = router
 }
 
 func (s *SenderSuite) TeardownTest() {
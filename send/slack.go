@@ -1,12 +1,14 @@
 package send
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log"
 	"os"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/bluele/slack"
 	"github.com/mongodb/grip/level"
@@ -74,20 +76,39 @@ func MakeSlackLogger(opts *SlackOptions) (Sender, error) {
 }
 
 func (s *slackJournal) Send(m message.Composer) {
-	if !s.level.ShouldLog(m) {
-		return
+	if err := s.SendContext(context.Background(), m); err != nil {
+		s.Base.mutex.RLock()
+		params := s.opts.getParams(m)
+		s.Base.mutex.RUnlock()
+
+		s.errHandler(err, message.NewFormattedMessage(m.Priority(),
+			"%s: %s\n", params.Attachments[0].Fallback, m.String()))
+	}
+}
+
+// SendContext posts the message to Slack. If the context is done
+// first, SendContext returns the context's error, although the message
+// may still be posted.
+func (s *slackJournal) SendContext(ctx context.Context, m message.Composer) error {
+	if !s.shouldLog(m) {
+		return nil
 	}
+	start := time.Now()
 
 	msg := m.String()
 
 	s.Base.mutex.RLock()
-	defer s.Base.mutex.RUnlock()
-
 	params := s.opts.getParams(m)
-	if err := s.client.ChatPostMessage(s.opts.Channel, msg, params); err != nil {
-		s.errHandler(err, message.NewFormattedMessage(m.Priority(),
-			"%s: %s\n", params.Attachments[0].Fallback, msg))
+	s.Base.mutex.RUnlock()
+
+	if err := runContext(ctx, func() error {
+		return s.client.ChatPostMessage(s.opts.Channel, msg, params)
+	}); err != nil {
+		return err
 	}
+
+	s.recordSend(m, start)
+	return nil
 }
 
 // SlackOptions configures the behavior for constructing messages sent
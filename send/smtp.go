@@ -2,6 +2,7 @@ package send
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"encoding/base64"
 	"errors"
@@ -13,6 +14,7 @@ import (
 	"os"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/mongodb/grip/message"
 )
@@ -73,13 +75,29 @@ func MakeSMTPLogger(opts *SMTPOptions) (Sender, error) {
 }
 
 func (s *smtpLogger) Send(m message.Composer) {
-	if s.level.ShouldLog(m) {
-		if err := s.opts.sendMail(m); err != nil {
-			s.errHandler(err, m)
-		}
+	if err := s.SendContext(context.Background(), m); err != nil {
+		s.errHandler(err, m)
 	}
 }
 
+// SendContext sends the message as an email, and returns when the
+// mail server has accepted it. If the context is done first,
+// SendContext returns the context's error, although the message may
+// still be delivered.
+func (s *smtpLogger) SendContext(ctx context.Context, m message.Composer) error {
+	if !s.shouldLog(m) {
+		return nil
+	}
+	start := time.Now()
+
+	if err := runContext(ctx, func() error { return s.opts.sendMail(m) }); err != nil {
+		return err
+	}
+
+	s.recordSend(m, start)
+	return nil
+}
+
 ///////////////////////////////////////////////////////////////////////////
 //
 // Implemntation of the Configuration Object
@@ -0,0 +1,265 @@
+package send
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mongodb/grip/level"
+	"github.com/mongodb/grip/message"
+)
+
+// number of recent delivery latencies used to compute the average
+// and percentile latency.
+const latencySamples = 1024
+
+// Stats reports on the messages that a Sender has handled.
+type Stats struct {
+	Name string `bson:"name" json:"name" yaml:"name"`
+
+	// Sent counts the messages the Sender delivered or passed
+	// on, by the name of their priority.
+	Sent map[string]int64 `bson:"sent" json:"sent" yaml:"sent"`
+
+	// Filtered counts messages that were not loggable or were
+	// below the Sender's threshold, and Dropped counts loggable
+	// messages that the Sender discarded for other reasons
+	// (e.g. sampling or deduplication.)
+	Filtered int64 `bson:"filtered" json:"filtered" yaml:"filtered"`
+	Dropped  int64 `bson:"dropped" json:"dropped" yaml:"dropped"`
+
+	// Errors counts the errors passed to the Sender's error
+	// handler, and LastError records the most recent of these.
+	Errors        int64     `bson:"errors" json:"errors" yaml:"errors"`
+	LastError     string    `bson:"last_error,omitempty" json:"last_error,omitempty" yaml:"last_error,omitempty"`
+	LastErrorTime time.Time `bson:"last_error_time,omitempty" json:"last_error_time,omitempty" yaml:"last_error_time,omitempty"`
+
+	// QueueDepth reports the number of messages that
+	// asynchronous Senders have accepted but not yet sent.
+	QueueDepth int64 `bson:"queue_depth" json:"queue_depth" yaml:"queue_depth"`
+
+	// AverageLatency and P99Latency describe how long recent
+	// calls to the Sender took to deliver messages.
+	AverageLatency time.Duration `bson:"average_latency" json:"average_latency" yaml:"average_latency"`
+	P99Latency     time.Duration `bson:"p99_latency" json:"p99_latency" yaml:"p99_latency"`
+}
+
+// StatsReporter is implemented by Senders that collect Stats. All
+// Senders built on Base, as well as the wrapper Senders in this
+// package, implement StatsReporter.
+type StatsReporter interface {
+	Stats() Stats
+}
+
+// senderStats collects the data for a Stats document. All methods are
+// safe to call on a nil instance, which does nothing.
+type senderStats struct {
+	sent      map[level.Priority]int64
+	filtered  int64
+	dropped   int64
+	errors    int64
+	lastErr   string
+	lastErrAt time.Time
+	queued    int64
+	latencies []time.Duration
+	next      int
+	mutex     sync.Mutex
+}
+
+func newSenderStats() *senderStats {
+	return &senderStats{sent: map[level.Priority]int64{}}
+}
+
+func (s *senderStats) recordSent(p level.Priority, latency time.Duration) {
+	if s == nil {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.sent[p]++
+
+	if len(s.latencies) < latencySamples {
+		s.latencies = append(s.latencies, latency)
+	} else {
+		s.latencies[s.next] = latency
+		s.next = (s.next + 1) % latencySamples
+	}
+}
+
+func (s *senderStats) recordFiltered() {
+	if s == nil {
+		return
+	}
+
+	s.mutex.Lock()
+	s.filtered++
+	s.mutex.Unlock()
+}
+
+func (s *senderStats) recordDropped() {
+	if s == nil {
+		return
+	}
+
+	s.mutex.Lock()
+	s.dropped++
+	s.mutex.Unlock()
+}
+
+func (s *senderStats) recordError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.errors++
+	s.lastErr = err.Error()
+	s.lastErrAt = time.Now()
+}
+
+func (s *senderStats) addQueued(n int64) {
+	if s == nil {
+		return
+	}
+
+	s.mutex.Lock()
+	s.queued += n
+	s.mutex.Unlock()
+}
+
+func (s *senderStats) snapshot(name string) Stats {
+	out := Stats{Name: name, Sent: map[string]int64{}}
+	if s == nil {
+		return out
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for p, count := range s.sent {
+		out.Sent[p.String()] += count
+	}
+
+	out.Filtered = s.filtered
+	out.Dropped = s.dropped
+	out.Errors = s.errors
+	out.LastError = s.lastErr
+	out.LastErrorTime = s.lastErrAt
+	out.QueueDepth = s.queued
+
+	if len(s.latencies) > 0 {
+		sorted := make([]time.Duration, len(s.latencies))
+		copy(sorted, s.latencies)
+		sort.Sort(durations(sorted))
+
+		var total time.Duration
+		for _, l := range sorted {
+			total += l
+		}
+
+		out.AverageLatency = total / time.Duration(len(sorted))
+		out.P99Latency = sorted[(len(sorted)*99)/100]
+	}
+
+	return out
+}
+
+type durations []time.Duration
+
+func (d durations) Len() int           { return len(d) }
+func (d durations) Less(i, j int) bool { return d[i] < d[j] }
+func (d durations) Swap(i, j int)      { d[i], d[j] = d[j], d[i] }
+
+// StatsNode describes a Sender and the Senders that it sends messages
+// to, as returned by CollectStats.
+type StatsNode struct {
+	Name     string      `bson:"name" json:"name" yaml:"name"`
+	Type     string      `bson:"type" json:"type" yaml:"type"`
+	Level    LevelInfo   `bson:"level" json:"level" yaml:"level"`
+	Stats    *Stats      `bson:"stats,omitempty" json:"stats,omitempty" yaml:"stats,omitempty"`
+	Children []StatsNode `bson:"children,omitempty" json:"children,omitempty" yaml:"children,omitempty"`
+}
+
+// CollectStats walks a tree of Senders, following the members of
+// multi and router Senders and the Senders wrapped by buffered and
+// other wrapper Senders, and reports the Stats for each Sender that
+// implements StatsReporter.
+func CollectStats(s Sender) StatsNode {
+	node := StatsNode{
+		Name:  s.Name(),
+		Type:  fmt.Sprintf("%T", s),
+		Level: s.Level(),
+	}
+
+	if reporter, ok := s.(StatsReporter); ok {
+		stats := reporter.Stats()
+		node.Stats = &stats
+	}
+
+	for _, member := range Members(s) {
+		node.Children = append(node.Children, CollectStats(member))
+	}
+
+	return node
+}
+
+// senderWrapper provides the Stats and members methods of the Senders
+// that wrap a single Sender, which embed it in place of the Sender.
+type senderWrapper struct {
+	Sender
+	stats *senderStats
+}
+
+func newSenderWrapper(s Sender) senderWrapper {
+	return senderWrapper{Sender: s, stats: newSenderStats()}
+}
+
+// Stats returns a report of the messages that the Sender has handled.
+func (w *senderWrapper) Stats() Stats { return w.stats.snapshot(w.Name()) }
+
+func (w *senderWrapper) members() []Sender { return []Sender{w.Sender} }
+
+// memberSender is implemented by Senders that send messages to other
+// Senders.
+type memberSender interface {
+	members() []Sender
+}
+
+// Members returns the Senders that multi, router, buffered and the
+// other wrapper Senders in this package send messages to. Returns nil
+// for other Senders.
+func Members(s Sender) []Sender {
+	if ms, ok := s.(memberSender); ok {
+		return ms.members()
+	}
+
+	return nil
+}
+
+// recordSend is called by Base-backed Senders once they have delivered
+// a message. Senders must not call it for messages that they failed to
+// deliver, which their error handler counts as errors.
+func (b *Base) recordSend(m message.Composer, start time.Time) {
+	b.stats.recordSent(m.Priority(), time.Since(start))
+}
+
+// shouldLog wraps the ShouldLog method of the Sender's level
+// configuration, and counts the messages that it filters.
+func (b *Base) shouldLog(m message.Composer) bool {
+	if b.Level().ShouldLog(m) {
+		return true
+	}
+
+	b.stats.recordFiltered()
+	return false
+}
+
+// Stats returns a report of the messages that the Sender has handled.
+func (b *Base) Stats() Stats {
+	return b.stats.snapshot(b.Name())
+}
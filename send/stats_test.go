@@ -0,0 +1,170 @@
+package send
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mongodb/grip/level"
+	"github.com/mongodb/grip/message"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBaseStatsCountsSendsFiltersAndErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	sink, err := NewInternalLogger("stats", LevelInfo{level.Info, level.Info})
+	assert.NoError(err)
+
+	sink.Send(message.NewDefaultMessage(level.Error, "one"))
+	sink.Send(message.NewDefaultMessage(level.Error, "two"))
+	sink.Send(message.NewDefaultMessage(level.Info, "three"))
+	sink.Send(message.NewDefaultMessage(level.Debug, "filtered"))
+	sink.Send(message.NewDefaultMessage(level.Error, ""))
+
+	stats := sink.Stats()
+	assert.Equal("stats", stats.Name)
+	assert.Equal(int64(2), stats.Sent[level.Error.String()])
+	assert.Equal(int64(1), stats.Sent[level.Info.String()])
+	assert.Equal(int64(2), stats.Filtered)
+	assert.Equal(int64(0), stats.Errors)
+	assert.True(stats.P99Latency >= stats.AverageLatency)
+
+	base := NewBase("base")
+	base.ErrorHandler(errors.New("broken"), message.NewString("msg"))
+	assert.NoError(base.SetErrorHandler(func(error, message.Composer) {}))
+	base.ErrorHandler(errors.New("still broken"), message.NewString("msg"))
+	base.ErrorHandler(nil, message.NewString("msg"))
+
+	stats = base.Stats()
+	assert.Equal("base", stats.Name)
+	assert.Equal(int64(2), stats.Errors)
+	assert.Equal("still broken", stats.LastError)
+	assert.False(stats.LastErrorTime.IsZero())
+}
+
+func TestBaseStatsDoNotCountFailedSendsAsSent(t *testing.T) {
+	assert := assert.New(t)
+
+	s, err := NewNativeLogger("failing", LevelInfo{level.Info, level.Info})
+	assert.NoError(err)
+	assert.NoError(s.SetErrorHandler(func(error, message.Composer) {}))
+	assert.NoError(s.SetFormatter(func(message.Composer) (string, error) { return "", errors.New("unformattable") }))
+
+	s.Send(message.NewDefaultMessage(level.Info, "lost"))
+
+	stats := s.(StatsReporter).Stats()
+	assert.Empty(stats.Sent)
+	assert.Equal(int64(1), stats.Errors)
+	assert.Equal("unformattable", stats.LastError)
+}
+
+func TestWrapperStatsDoNotCountFailedSendsAsSent(t *testing.T) {
+	assert := assert.New(t)
+
+	failing := func() Sender {
+		s, err := NewNativeLogger("failing", LevelInfo{level.Info, level.Info})
+		assert.NoError(err)
+		assert.NoError(s.SetErrorHandler(func(error, message.Composer) {}))
+		assert.NoError(s.SetFormatter(func(message.Composer) (string, error) { return "", errors.New("unformattable") }))
+		return s
+	}
+
+	redacting, err := NewRedactingSender(failing(), &RedactionOptions{MaskKeys: []string{"password"}})
+	assert.NoError(err)
+	escalating, err := NewEscalatingSender(failing(), EscalationOptions{Count: 2})
+	assert.NoError(err)
+	router, err := NewRouterSender("router", LevelInfo{level.Info, level.Info}, []Sender{failing()})
+	assert.NoError(err)
+
+	for name, s := range map[string]Sender{
+		"enrich":   NewEnrichingSender(failing(), EnrichmentOptions{}),
+		"redact":   redacting,
+		"sample":   NewSamplingSender(failing()),
+		"escalate": escalating,
+		"router":   router,
+	} {
+		assert.Error(SendContext(context.Background(), s, message.NewDefaultMessage(level.Info, "lost")), name)
+		assert.Empty(s.(StatsReporter).Stats().Sent, name)
+	}
+
+	flaky := newFlakySender()
+	flaky.setFailing(true)
+	breaker, err := NewCircuitBreakerSender(flaky, CircuitBreakerOptions{Threshold: 5})
+	assert.NoError(err)
+	breaker.Send(message.NewDefaultMessage(level.Info, "lost"))
+
+	stats := breaker.(StatsReporter).Stats()
+	assert.Empty(stats.Sent)
+	assert.Equal(int64(1), stats.Errors)
+}
+
+func TestWrapperStatsCountDroppedMessages(t *testing.T) {
+	assert := assert.New(t)
+
+	sink, err := NewInternalLogger("sink", LevelInfo{level.Info, level.Info})
+	assert.NoError(err)
+
+	s := NewSamplingSender(sink, func(m message.Composer) bool { return m.String() != "drop" })
+	s.Send(message.NewDefaultMessage(level.Info, "keep"))
+	s.Send(message.NewDefaultMessage(level.Info, "drop"))
+	s.Send(message.NewDefaultMessage(level.Debug, "keep"))
+
+	stats := s.(StatsReporter).Stats()
+	assert.Equal(int64(1), stats.Sent[level.Info.String()])
+	assert.Equal(int64(1), stats.Dropped)
+	assert.Equal(int64(1), stats.Filtered)
+}
+
+func TestBufferedStatsTrackQueueDepth(t *testing.T) {
+	assert := assert.New(t)
+
+	sink, err := NewInternalLogger("sink", LevelInfo{level.Info, level.Info})
+	assert.NoError(err)
+
+	s := NewBufferedSender(sink, time.Hour, 10)
+	for i := 0; i < 5; i++ {
+		s.Send(message.NewDefaultMessage(level.Info, "queued"))
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(int64(5), s.(StatsReporter).Stats().QueueDepth)
+	assert.NoError(s.Close())
+}
+
+func TestCollectStatsWalksSenderTree(t *testing.T) {
+	assert := assert.New(t)
+
+	one, err := NewInternalLogger("one", LevelInfo{level.Info, level.Info})
+	assert.NoError(err)
+	two, err := NewInternalLogger("two", LevelInfo{level.Info, level.Info})
+	assert.NoError(err)
+
+	multi, err := NewMultiSender("multi", LevelInfo{level.Info, level.Info}, []Sender{one, two})
+	assert.NoError(err)
+	root := NewSamplingSender(multi)
+
+	root.Send(message.NewDefaultMessage(level.Warning, "hello"))
+
+	node := CollectStats(root)
+	assert.Equal("multi", node.Name)
+	assert.Equal("*send.samplingSender", node.Type)
+	assert.NotNil(node.Stats)
+	assert.Equal(int64(1), node.Stats.Sent[level.Warning.String()])
+
+	assert.Len(node.Children, 1)
+	assert.Equal("*send.multiSender", node.Children[0].Type)
+	assert.Equal(int64(1), node.Children[0].Stats.Sent[level.Warning.String()])
+
+	leaves := node.Children[0].Children
+	assert.Len(leaves, 2)
+	for _, leaf := range leaves {
+		assert.Equal("*send.InternalSender", leaf.Type)
+		assert.Empty(leaf.Children)
+		assert.Equal(int64(1), leaf.Stats.Sent[level.Warning.String()])
+	}
+
+	assert.Nil(Members(one))
+	assert.Len(Members(multi), 2)
+}
@@ -1,10 +1,12 @@
 package send
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/mongodb/grip/message"
 )
@@ -48,15 +50,31 @@ func MakeStreamLogger(ws WriteStringer) Sender {
 }
 
 func (s *streamLogger) Send(m message.Composer) {
-	if s.level.ShouldLog(m) {
-		msg := m.String()
+	if err := s.SendContext(context.Background(), m); err != nil {
+		s.errHandler(err, m)
+	}
+}
 
-		if !strings.HasSuffix(msg, "\n") {
-			msg += "\n"
-		}
+func (s *streamLogger) SendContext(ctx context.Context, m message.Composer) error {
+	if !s.shouldLog(m) {
+		return nil
+	}
+	start := time.Now()
 
-		if _, err := s.fobj.WriteString(msg); err != nil {
-			s.errHandler(err, m)
-		}
+	if err := ctx.Err(); err != nil {
+		return err
 	}
+
+	msg := m.String()
+
+	if !strings.HasSuffix(msg, "\n") {
+		msg += "\n"
+	}
+
+	if _, err := s.fobj.WriteString(msg); err != nil {
+		return err
+	}
+
+	s.recordSend(m, start)
+	return nil
 }
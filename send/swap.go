@@ -0,0 +1,127 @@
+package send
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mongodb/grip/message"
+)
+
+// SwapSender is a Sender that sends messages to another Sender, which
+// can be replaced while other goroutines are sending messages, for
+// example to apply a new logging configuration without restarting the
+// process. The SwapSender filters messages using its own level; the
+// levels of the Sender that it wraps are not modified, so the levels
+// of the Senders in a configured tree are preserved when you pass a
+// SwapSender to Journaler.SetSender.
+type SwapSender struct {
+	sender Sender
+	swap   sync.RWMutex
+	*Base
+}
+
+// NewSwapSender wraps the Sender in a SwapSender. The SwapSender
+// starts with the name and levels of the Sender.
+func NewSwapSender(sender Sender) *SwapSender {
+	s := &SwapSender{
+		sender: sender,
+		Base:   NewBase(sender.Name()),
+	}
+
+	_ = s.Base.SetLevel(sender.Level())
+
+	return s
+}
+
+// Current returns the Sender that currently receives messages.
+func (s *SwapSender) Current() Sender {
+	s.swap.RLock()
+	defer s.swap.RUnlock()
+
+	return s.sender
+}
+
+// Swap replaces the Sender that receives messages. Swap waits for
+// messages that other goroutines are sending to the previous Sender to
+// be delivered, and then flushes and closes the previous Sender, so
+// that no messages are lost.
+func (s *SwapSender) Swap(sender Sender) error {
+	if sender == nil {
+		return errors.New("cannot swap in a nil sender")
+	}
+
+	s.swap.Lock()
+	prev := s.sender
+	s.sender = sender
+	s.swap.Unlock()
+
+	if prev == sender {
+		return nil
+	}
+
+	errs := []string{}
+	if err := Flush(context.Background(), prev); err != nil {
+		errs = append(errs, err.Error())
+	}
+
+	if err := prev.Close(); err != nil {
+		errs = append(errs, err.Error())
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "\n"))
+	}
+
+	return nil
+}
+
+func (s *SwapSender) Send(m message.Composer) {
+	if !s.shouldLog(m) {
+		return
+	}
+	defer s.recordSend(m, time.Now())
+
+	s.swap.RLock()
+	defer s.swap.RUnlock()
+
+	s.sender.Send(m)
+}
+
+// SendContext sends the message to the current Sender. Swap waits for
+// SendContext to return before replacing the Sender.
+func (s *SwapSender) SendContext(ctx context.Context, m message.Composer) error {
+	if !s.shouldLog(m) {
+		return nil
+	}
+	start := time.Now()
+
+	s.swap.RLock()
+	defer s.swap.RUnlock()
+
+	if err := SendContext(ctx, s.sender, m); err != nil {
+		return err
+	}
+
+	s.recordSend(m, start)
+	return nil
+}
+
+// Flush flushes the current Sender.
+func (s *SwapSender) Flush(ctx context.Context) error {
+	return Flush(ctx, s.Current())
+}
+
+// SetFormatter sets the formatter of the current Sender.
+func (s *SwapSender) SetFormatter(mf MessageFormatter) error {
+	return s.Current().SetFormatter(mf)
+}
+
+// Close closes the current Sender.
+func (s *SwapSender) Close() error {
+	return s.Current().Close()
+}
+
+func (s *SwapSender) members() []Sender { return []Sender{s.Current()} }
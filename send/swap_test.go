@@ -0,0 +1,86 @@
+package send
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mongodb/grip/level"
+	"github.com/mongodb/grip/message"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSwapSenderKeepsLevelsOfWrappedSender(t *testing.T) {
+	assert := assert.New(t)
+
+	inner, err := NewInternalLogger("inner", LevelInfo{level.Info, level.Warning})
+	assert.NoError(err)
+
+	s := NewSwapSender(inner)
+	assert.Equal("inner", s.Name())
+	assert.Equal(inner.Level(), s.Level())
+
+	assert.NoError(s.SetLevel(LevelInfo{level.Info, level.Debug}))
+	assert.Equal(level.Warning, inner.Level().Threshold)
+
+	// the wrapped sender applies its own threshold
+	s.Send(message.NewDefaultMessage(level.Info, "filtered"))
+	assert.False(inner.GetMessage().Logged)
+	s.Send(message.NewDefaultMessage(level.Error, "sent"))
+	assert.True(inner.GetMessage().Logged)
+
+	// the swap sender applies its threshold
+	s.Send(message.NewDefaultMessage(level.Trace, "filtered"))
+	assert.False(inner.HasMessage())
+	assert.EqualValues(1, s.Stats().Filtered)
+
+	assert.Error(s.Swap(nil))
+	assert.NoError(s.Swap(inner))
+	assert.Equal(inner, s.Current())
+	assert.Equal([]Sender{inner}, Members(s))
+}
+
+func TestSwapSenderDeliversMessagesDuringSwap(t *testing.T) {
+	assert := assert.New(t)
+	l := LevelInfo{level.Info, level.Info}
+
+	sinks := []*InternalSender{}
+	for i := 0; i < 5; i++ {
+		sink, err := NewInternalLogger("sink", l)
+		assert.NoError(err)
+		sinks = append(sinks, sink)
+	}
+
+	s := NewSwapSender(NewBufferedSender(sinks[0], time.Hour, 1000))
+	assert.NoError(s.SetLevel(l))
+
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 250; j++ {
+				s.Send(message.NewDefaultMessage(level.Info, "concurrent"))
+			}
+		}()
+	}
+
+	for _, sink := range sinks[1:] {
+		assert.NoError(s.Swap(NewBufferedSender(sink, time.Hour, 1000)))
+	}
+	wg.Wait()
+	assert.NoError(s.Close())
+
+	count := 0
+	for _, sink := range sinks {
+		for sink.HasMessage() {
+			m := sink.GetMessage()
+			if group, ok := m.Message.(*message.GroupComposer); ok {
+				count += len(group.Messages())
+			} else {
+				count++
+			}
+		}
+	}
+	assert.Equal(1000, count)
+}
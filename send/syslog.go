@@ -3,10 +3,12 @@
 package send
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"log/syslog"
 	"os"
+	"time"
 
 	"github.com/mongodb/grip/level"
 	"github.com/mongodb/grip/message"
@@ -32,6 +34,12 @@ func NewSyslogLogger(name, network, raddr string, l LevelInfo) (Sender, error) {
 // posts to systemd's journal.
 // Pass to Journaler.SetSender or call SetName before using.
 func MakeSysLogger(network, raddr string) Sender {
+	return makeSysLogger(network, raddr, syslog.LOG_DEBUG)
+}
+
+// makeSysLogger constructs a syslog Sender that sends messages with
+// the facility of the priority.
+func makeSysLogger(network, raddr string, priority syslog.Priority) Sender {
 	s := &syslogger{Base: NewBase("")}
 
 	fallback := log.New(os.Stdout, "", log.LstdFlags)
@@ -47,7 +55,7 @@ func MakeSysLogger(network, raddr string) Sender {
 			}
 		}
 
-		w, err := syslog.Dial(network, raddr, syslog.LOG_DEBUG, s.Name())
+		w, err := syslog.Dial(network, raddr, priority, s.Name())
 		if err != nil {
 			s.errHandler(err, message.NewErrorWrapMessage(level.Error, err,
 				"error restarting syslog [%s] for logger: %s", err.Error(), s.Name()))
@@ -76,13 +84,27 @@ func MakeLocalSyslogLogger() Sender {
 func (s *syslogger) Close() error { return s.logger.Close() }
 
 func (s *syslogger) Send(m message.Composer) {
-	if s.level.ShouldLog(m) {
-		if err := s.sendToSysLog(m.Priority(), m.String()); err != nil {
-			s.errHandler(err, m)
-		}
+	if err := s.SendContext(context.Background(), m); err != nil {
+		s.errHandler(err, m)
 	}
 }
 
+func (s *syslogger) SendContext(ctx context.Context, m message.Composer) error {
+	if !s.shouldLog(m) {
+		return nil
+	}
+	start := time.Now()
+
+	if err := runContext(ctx, func() error {
+		return s.sendToSysLog(m.Priority(), m.String())
+	}); err != nil {
+		return err
+	}
+
+	s.recordSend(m, start)
+	return nil
+}
+
 func (s *syslogger) sendToSysLog(p level.Priority, message string) error {
 	switch p {
 	case level.Emergency:
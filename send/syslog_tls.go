@@ -0,0 +1,168 @@
+//go:build linux || freebsd || solaris || darwin
+// +build linux freebsd solaris darwin
+
+package send
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log"
+	"log/syslog"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mongodb/grip/level"
+	"github.com/mongodb/grip/message"
+)
+
+const syslogTLSDialTimeout = 10 * time.Second
+
+// syslogTLSSender sends messages to a remote syslog service over TLS,
+// as RFC 5424 messages with octet-counting framing (RFC 5425), which
+// the standard library's syslog client does not support.
+type syslogTLSSender struct {
+	addr     string
+	facility syslog.Priority
+	config   *tls.Config
+	hostname string
+	conn     net.Conn
+	mutex    sync.Mutex
+	*Base
+}
+
+// makeSyslogTLSSender constructs a Sender that sends messages, with
+// the facility, to the syslog service at the address over TLS. The
+// Sender connects when it sends its first message, and reconnects
+// after errors.
+func makeSyslogTLSSender(addr string, facility syslog.Priority, config *tls.Config) Sender {
+	s := &syslogTLSSender{
+		addr:     addr,
+		facility: facility,
+		config:   config,
+		Base:     NewBase(""),
+	}
+
+	s.hostname, _ = os.Hostname()
+	if s.hostname == "" {
+		s.hostname = "-"
+	}
+
+	fallback := log.New(os.Stdout, "", log.LstdFlags)
+	_ = s.SetErrorHandler(ErrorHandlerFromLogger(fallback))
+
+	s.reset = func() {
+		fallback.SetPrefix(fmt.Sprintf("[%s]", s.Name()))
+	}
+
+	s.closer = func() error {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+
+		if s.conn == nil {
+			return nil
+		}
+
+		err := s.conn.Close()
+		s.conn = nil
+
+		return err
+	}
+
+	return s
+}
+
+func (s *syslogTLSSender) Send(m message.Composer) {
+	if err := s.SendContext(context.Background(), m); err != nil {
+		s.errHandler(err, m)
+	}
+}
+
+func (s *syslogTLSSender) SendContext(ctx context.Context, m message.Composer) error {
+	if !s.shouldLog(m) {
+		return nil
+	}
+	start := time.Now()
+
+	frame := s.frame(m, start)
+	if err := runContext(ctx, func() error { return s.write(frame) }); err != nil {
+		return err
+	}
+
+	s.recordSend(m, start)
+	return nil
+}
+
+// frame renders the message as an RFC 5424 message, prefixed with its
+// length.
+func (s *syslogTLSSender) frame(m message.Composer, ts time.Time) []byte {
+	app := strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' {
+			return '_'
+		}
+		return r
+	}, s.Name())
+	if len(app) > 48 {
+		app = app[:48]
+	}
+	if app == "" {
+		app = "-"
+	}
+
+	msg := fmt.Sprintf("<%d>1 %s %s %s %d - - %s",
+		s.facility|syslogSeverity(m.Priority()), ts.Format(time.RFC3339Nano),
+		s.hostname, app, os.Getpid(), m.String())
+
+	return []byte(fmt.Sprintf("%d %s", len(msg), msg))
+}
+
+// write sends the frame, connecting if needed, and reconnects and
+// retries once if the connection fails.
+func (s *syslogTLSSender) write(frame []byte) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		if s.conn == nil {
+			dialer := &net.Dialer{Timeout: syslogTLSDialTimeout}
+			if s.conn, err = tls.DialWithDialer(dialer, "tcp", s.addr, s.config); err != nil {
+				s.conn = nil
+				return fmt.Errorf("problem connecting to syslog at '%s': %s", s.addr, err.Error())
+			}
+		}
+
+		if _, err = s.conn.Write(frame); err == nil {
+			return nil
+		}
+
+		_ = s.conn.Close()
+		s.conn = nil
+	}
+
+	return err
+}
+
+func syslogSeverity(p level.Priority) syslog.Priority {
+	switch p {
+	case level.Emergency:
+		return syslog.LOG_EMERG
+	case level.Alert:
+		return syslog.LOG_ALERT
+	case level.Critical:
+		return syslog.LOG_CRIT
+	case level.Error:
+		return syslog.LOG_ERR
+	case level.Warning:
+		return syslog.LOG_WARNING
+	case level.Notice:
+		return syslog.LOG_NOTICE
+	case level.Info:
+		return syslog.LOG_INFO
+	default:
+		return syslog.LOG_DEBUG
+	}
+}
@@ -0,0 +1,162 @@
+//go:build linux || freebsd || solaris || darwin
+// +build linux freebsd solaris darwin
+
+package send
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"log/syslog"
+	"math/big"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mongodb/grip/level"
+	"github.com/mongodb/grip/message"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestTLSListener returns a TLS listener on the loopback interface,
+// with a self-signed certificate, and a client configuration that
+// trusts it.
+func newTestTLSListener(t *testing.T) (net.Listener, *tls.Config) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IsCA:         true,
+
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}},
+	})
+	require.NoError(t, err)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(cert)
+
+	return ln, &tls.Config{RootCAs: roots}
+}
+
+// readSyslogFrame reads an octet-counted syslog frame.
+func readSyslogFrame(r *bufio.Reader) (string, error) {
+	size, err := r.ReadString(' ')
+	if err != nil {
+		return "", err
+	}
+
+	n, err := strconv.Atoi(strings.TrimSpace(size))
+	if err != nil {
+		return "", err
+	}
+
+	buf := make([]byte, n)
+	if _, err = io.ReadFull(r, buf); err != nil {
+		return "", err
+	}
+
+	return string(buf), nil
+}
+
+func TestSyslogTLSSender(t *testing.T) {
+	assert := assert.New(t)
+
+	ln, config := newTestTLSListener(t)
+	defer ln.Close()
+
+	frames := make(chan string, 2)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		r := bufio.NewReader(conn)
+		for {
+			frame, err := readSyslogFrame(r)
+			if err != nil {
+				close(frames)
+				return
+			}
+			frames <- frame
+		}
+	}()
+
+	s := makeSyslogTLSSender(ln.Addr().String(), syslog.LOG_LOCAL3, config)
+	s.SetName("my app")
+	require.NoError(t, s.SetLevel(LevelInfo{Default: level.Info, Threshold: level.Info}))
+
+	s.Send(message.NewDefaultMessage(level.Notice, "over tls"))
+	s.Send(message.NewDefaultMessage(level.Error, "line one\nline two"))
+
+	for _, expected := range []struct {
+		pri  string
+		text string
+	}{
+		// local3 (19 << 3) | notice (5), and | err (3)
+		{"<157>1 ", " my_app "},
+		{"<155>1 ", " - - line one\nline two"},
+	} {
+		select {
+		case frame := <-frames:
+			assert.True(strings.HasPrefix(frame, expected.pri), frame)
+			assert.Contains(frame, expected.text)
+		case <-time.After(5 * time.Second):
+			assert.Fail("timed out waiting for a syslog message")
+		}
+	}
+
+	assert.NoError(s.Close())
+	stats := s.(StatsReporter).Stats()
+	assert.EqualValues(1, stats.Sent[level.Notice.String()])
+	assert.EqualValues(1, stats.Sent[level.Error.String()])
+}
+
+func TestSyslogTLSSenderReportsConnectionErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := ln.Addr().String()
+	assert.NoError(ln.Close())
+
+	s := makeSyslogTLSSender(addr, syslog.LOG_USER, &tls.Config{})
+	s.SetName("app")
+	require.NoError(t, s.SetLevel(LevelInfo{Default: level.Info, Threshold: level.Info}))
+
+	errs := 0
+	assert.NoError(s.SetErrorHandler(func(error, message.Composer) { errs++ }))
+	s.Send(message.NewDefaultMessage(level.Info, "lost"))
+	assert.Equal(1, errs)
+	assert.Empty(s.(StatsReporter).Stats().Sent)
+
+	// urls build the sender without connecting
+	s, err = FromURL("syslog+tls://collector.invalid?facility=local3")
+	assert.NoError(err)
+	assert.Equal("collector.invalid:6514", s.(*syslogTLSSender).addr)
+	assert.NoError(s.Close())
+}
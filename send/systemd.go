@@ -3,9 +3,13 @@
 package send
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/coreos/go-systemd/journal"
 	"github.com/mongodb/grip/level"
@@ -46,11 +50,92 @@ func MakeSystemdLogger() Sender {
 func (s *systemdJournal) Close() error { return nil }
 
 func (s *systemdJournal) Send(m message.Composer) {
-	if s.level.ShouldLog(m) {
-		err := journal.Send(m.String(), s.level.convertPrioritySystemd(m.Priority()), s.options)
-		if err != nil {
-			s.errHandler(err, m)
+	if err := s.SendContext(context.Background(), m); err != nil {
+		s.errHandler(err, m)
+	}
+}
+
+func (s *systemdJournal) SendContext(ctx context.Context, m message.Composer) error {
+	if !s.shouldLog(m) {
+		return nil
+	}
+	start := time.Now()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if err := journal.Send(m.String(), s.level.convertPrioritySystemd(m.Priority()), s.fields(m)); err != nil {
+		return err
+	}
+
+	s.recordSend(m, start)
+	return nil
+}
+
+// fields returns the journal fields for a message: the configured
+// options, plus the contents of messages whose Raw form is a
+// message.Fields value, with names converted to journal field names,
+// and the call site of the message, if known. Fields whose names are
+// reserved by the journal, or that start with an underscore, are
+// skipped, because the journal sets them itself.
+func (s *systemdJournal) fields(m message.Composer) map[string]string {
+	raw, _ := m.Raw().(message.Fields)
+	frame, hasCallSite := message.GetCallSite(m)
+	if len(raw) == 0 && !hasCallSite {
+		return s.options
+	}
+
+	out := make(map[string]string, len(s.options)+len(raw)+3)
+	for k, v := range raw {
+		if k == "msg" || k == "time" || strings.HasPrefix(k, "_") {
+			continue
+		}
+
+		if name := journalFieldName(k); name != "" && !isReservedJournalField(name) {
+			out[name] = fmt.Sprint(v)
+		}
+	}
+
+	if hasCallSite {
+		out["CODE_FILE"] = frame.File
+		out["CODE_LINE"] = strconv.Itoa(frame.Line)
+		out["CODE_FUNC"] = frame.Function
+	}
+
+	for k, v := range s.options {
+		out[k] = v
+	}
+
+	return out
+}
+
+// journalFieldName converts a field name into a valid journal field
+// name, which may only contain upper case letters, digits, and
+// underscores, and may not start with an underscore or a digit.
+func journalFieldName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			return r
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		default:
+			return '_'
 		}
+	}, name)
+
+	return strings.TrimLeft(name, "_0123456789")
+}
+
+// isReservedJournalField reports whether a journal field name is set
+// by the journal or the Sender, and must not be set from message fields.
+func isReservedJournalField(name string) bool {
+	switch name {
+	case "MESSAGE", "PRIORITY", "SYSLOG_IDENTIFIER":
+		return true
+	default:
+		return strings.HasPrefix(name, "CODE_")
 	}
 }
 
@@ -0,0 +1,50 @@
+// +build linux
+
+package send
+
+import (
+	"testing"
+
+	"github.com/mongodb/grip/level"
+	"github.com/mongodb/grip/message"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSystemdJournalFields(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("SERVICE", journalFieldName("service"))
+	assert.Equal("K8S_POD", journalFieldName("k8s.pod"))
+	assert.Equal("REQUEST_ID", journalFieldName("_1request-id"))
+	assert.Equal("", journalFieldName("__"))
+
+	s := MakeSystemdLogger().(*systemdJournal)
+	s.options["SYSLOG_IDENTIFIER"] = "grip"
+
+	assert.Equal(s.options, s.fields(message.NewDefaultMessage(level.Info, "plain")))
+
+	fields := s.fields(message.NewFieldsMessage(level.Info, "hi", message.Fields{
+		"service":           "api",
+		"syslog_identifier": "other",
+		"priority":          "info",
+		"message":           "conflicts",
+		"code_line":         7,
+		"_pid":              1,
+	}))
+	assert.Equal("api", fields["SERVICE"])
+	assert.Equal("grip", fields["SYSLOG_IDENTIFIER"])
+	assert.NotContains(fields, "PRIORITY")
+	assert.NotContains(fields, "MESSAGE")
+	assert.NotContains(fields, "CODE_LINE")
+	assert.NotContains(fields, "PID")
+	assert.NotContains(fields, "MSG")
+	assert.NotContains(fields, "TIME")
+
+	m := message.NewDefaultMessage(level.Info, "plain").(message.CallSiteComposer)
+	m.SetCallSite(message.StackFrame{Function: "main.main", File: "/src/main.go", Line: 42})
+	fields = s.fields(m)
+	assert.Equal("main.main", fields["CODE_FUNC"])
+	assert.Equal("/src/main.go", fields["CODE_FILE"])
+	assert.Equal("42", fields["CODE_LINE"])
+	assert.Equal("grip", fields["SYSLOG_IDENTIFIER"])
+}
@@ -0,0 +1,285 @@
+package send
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mongodb/grip/level"
+	"github.com/mongodb/grip/message"
+)
+
+// TailSender is a Sender that streams messages to HTTP clients, so
+// that you can follow the logs of a running process, for example with
+// "curl -N http://host:port/tail?priority=warning". TailSender is
+// also an http.Handler, which streams messages as server-sent events
+// (i.e. the text/event-stream content type), in which the data of
+// each event is the JSON form of the message's Raw form.
+//
+// Clients can filter the messages that they receive with the
+// following query parameters:
+//
+//    priority: the lowest priority of messages to receive.
+//    name: a glob pattern (as in path.Match) that matches the
+//        logger name, which is the value of the "logger" field in
+//        messages whose Raw form is a message.Fields value, and is
+//        otherwise the name of the Sender. May be repeated.
+//    field: a "key:value" pair that messages whose Raw form is a
+//        message.Fields value must contain, comparing the string
+//        form of the field's value. May be repeated.
+//
+// Sending a message never blocks: each client has a queue of
+// messages, and the Sender disconnects clients whose queues are full.
+type TailSender struct {
+	queueSize int
+	clients   map[*tailClient]struct{}
+	closed    bool
+	lock      sync.RWMutex
+	*Base
+}
+
+// TailOptions configures a TailSender.
+type TailOptions struct {
+	// QueueSize is the number of messages that the Sender holds
+	// for each client that has not yet received them, and
+	// defaults to 100.
+	QueueSize int
+}
+
+type tailClient struct {
+	filter  tailFilter
+	events  chan []byte
+	dropped bool
+}
+
+type tailFilter struct {
+	priority level.Priority
+	names    []string
+	fields   map[string]string
+}
+
+// NewTailSender constructs a TailSender with the specified name and
+// levels.
+func NewTailSender(name string, l LevelInfo, opts TailOptions) (*TailSender, error) {
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = 100
+	}
+
+	s := &TailSender{
+		queueSize: opts.QueueSize,
+		clients:   map[*tailClient]struct{}{},
+		Base:      NewBase(name),
+	}
+
+	if err := s.SetLevel(l); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Clients returns the number of connected clients.
+func (s *TailSender) Clients() int {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return len(s.clients)
+}
+
+func (s *TailSender) Send(m message.Composer) {
+	if !s.shouldLog(m) {
+		return
+	}
+	start := time.Now()
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if len(s.clients) == 0 {
+		s.recordSend(m, start)
+		return
+	}
+
+	data, err := json.Marshal(m.Raw())
+	if err != nil {
+		s.errHandler(err, m)
+		return
+	}
+	defer s.recordSend(m, start)
+
+	name := s.Name()
+	for client := range s.clients {
+		if !client.filter.matches(m, name) {
+			continue
+		}
+
+		select {
+		case client.events <- data:
+		default:
+			// the client isn't keeping up, so disconnect it
+			// rather than blocking.
+			s.stats.recordDropped()
+			client.dropped = true
+			delete(s.clients, client)
+			close(client.events)
+		}
+	}
+}
+
+// Close disconnects all of the clients.
+func (s *TailSender) Close() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.closed = true
+	for client := range s.clients {
+		delete(s.clients, client)
+		close(client.events)
+	}
+
+	return nil
+}
+
+func (s *TailSender) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, fmt.Sprintf("%s is not supported", r.Method), http.StatusMethodNotAllowed)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	filter, err := parseTailFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	client := &tailClient{filter: filter, events: make(chan []byte, s.queueSize)}
+
+	s.lock.Lock()
+	if s.closed {
+		s.lock.Unlock()
+		http.Error(w, "sender is closed", http.StatusServiceUnavailable)
+		return
+	}
+	s.clients[client] = struct{}{}
+	s.lock.Unlock()
+
+	defer s.disconnect(client)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	_, _ = fmt.Fprint(w, ": connected\n\n")
+	flusher.Flush()
+
+	id := 0
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case data, ok := <-client.events:
+			if !ok {
+				s.lock.RLock()
+				dropped := client.dropped
+				s.lock.RUnlock()
+
+				if dropped {
+					_, _ = fmt.Fprint(w, "event: dropped\ndata: client was too slow\n\n")
+					flusher.Flush()
+				}
+				return
+			}
+
+			id++
+			if _, err := fmt.Fprintf(w, "id: %d\ndata: %s\n\n", id, data); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func (s *TailSender) disconnect(client *tailClient) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if _, ok := s.clients[client]; ok {
+		delete(s.clients, client)
+		close(client.events)
+	}
+}
+
+func parseTailFilter(r *http.Request) (tailFilter, error) {
+	query := r.URL.Query()
+	filter := tailFilter{names: query["name"], fields: map[string]string{}}
+
+	if v := query.Get("priority"); v != "" {
+		if filter.priority = level.FromString(v); filter.priority == level.Invalid {
+			return filter, fmt.Errorf("priority '%s' is not valid", v)
+		}
+	}
+
+	for _, pattern := range filter.names {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return filter, fmt.Errorf("name pattern '%s' is not valid", pattern)
+		}
+	}
+
+	for _, field := range query["field"] {
+		parts := strings.SplitN(field, ":", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return filter, fmt.Errorf("field '%s' is not a 'key:value' pair", field)
+		}
+		filter.fields[parts[0]] = parts[1]
+	}
+
+	return filter, nil
+}
+
+func (f tailFilter) matches(m message.Composer, name string) bool {
+	if m.Priority() < f.priority {
+		return false
+	}
+
+	if len(f.names) == 0 && len(f.fields) == 0 {
+		return true
+	}
+
+	fields, _ := m.Raw().(message.Fields)
+
+	if len(f.names) > 0 {
+		if logger, ok := fields["logger"].(string); ok {
+			name = logger
+		}
+
+		matched := false
+		for _, pattern := range f.names {
+			if ok, _ := path.Match(pattern, name); ok {
+				matched = true
+				break
+			}
+		}
+
+		if !matched {
+			return false
+		}
+	}
+
+	for k, v := range f.fields {
+		actual, ok := fields[k]
+		if !ok || fmt.Sprint(actual) != v {
+			return false
+		}
+	}
+
+	return true
+}
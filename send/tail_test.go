@@ -0,0 +1,127 @@
+package send
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mongodb/grip/level"
+	"github.com/mongodb/grip/message"
+	"github.com/stretchr/testify/assert"
+)
+
+// readTailEvent returns the data of the next event in the stream.
+func readTailEvent(r *bufio.Reader) (string, error) {
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return "", err
+		}
+
+		if strings.HasPrefix(line, "data: ") {
+			return strings.TrimSpace(strings.TrimPrefix(line, "data: ")), nil
+		}
+	}
+}
+
+func TestTailSenderStreamsFilteredMessages(t *testing.T) {
+	assert := assert.New(t)
+
+	s, err := NewTailSender("tail", LevelInfo{level.Info, level.Info}, TailOptions{})
+	assert.NoError(err)
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "?priority=warning&name=api*&field=region:us-east-1")
+	assert.NoError(err)
+	defer resp.Body.Close()
+	assert.Equal(http.StatusOK, resp.StatusCode)
+	assert.Equal("text/event-stream", resp.Header.Get("Content-Type"))
+
+	reader := bufio.NewReader(resp.Body)
+	line, err := reader.ReadString('\n')
+	assert.NoError(err)
+	assert.Equal(": connected\n", line)
+	assert.Equal(1, s.Clients())
+
+	for _, m := range []message.Composer{
+		message.NewFields(level.Info, message.Fields{"logger": "api", "region": "us-east-1", "msg": "low priority"}),
+		message.NewFields(level.Error, message.Fields{"logger": "db", "region": "us-east-1", "msg": "other logger"}),
+		message.NewFields(level.Error, message.Fields{"logger": "api", "region": "eu-west-1", "msg": "other region"}),
+		message.NewDefaultMessage(level.Error, "not structured"),
+		message.NewFields(level.Error, message.Fields{"logger": "api-v2", "region": "us-east-1", "msg": "matched"}),
+	} {
+		s.Send(m)
+	}
+
+	data, err := readTailEvent(reader)
+	assert.NoError(err)
+	doc := map[string]interface{}{}
+	assert.NoError(json.Unmarshal([]byte(data), &doc))
+	assert.Equal("matched", doc["msg"])
+	assert.Equal("api-v2", doc["logger"])
+
+	// closing the sender ends the stream
+	assert.NoError(s.Close())
+	_, err = readTailEvent(reader)
+	assert.Error(err)
+	assert.Equal(0, s.Clients())
+
+	resp, err = http.Get(srv.URL)
+	assert.NoError(err)
+	assert.Equal(http.StatusServiceUnavailable, resp.StatusCode)
+}
+
+func TestTailSenderRejectsInvalidRequests(t *testing.T) {
+	assert := assert.New(t)
+
+	s, err := NewTailSender("tail", LevelInfo{level.Info, level.Info}, TailOptions{})
+	assert.NoError(err)
+
+	for query, code := range map[string]int{
+		"?priority=loud": http.StatusBadRequest,
+		"?name=[":        http.StatusBadRequest,
+		"?field=region":  http.StatusBadRequest,
+		"?field=:value":  http.StatusBadRequest,
+	} {
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/"+query, nil))
+		assert.Equal(code, rec.Code, query)
+	}
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", nil))
+	assert.Equal(http.StatusMethodNotAllowed, rec.Code)
+}
+
+func TestTailSenderDropsSlowClients(t *testing.T) {
+	assert := assert.New(t)
+
+	s, err := NewTailSender("tail", LevelInfo{level.Info, level.Info}, TailOptions{QueueSize: 2})
+	assert.NoError(err)
+
+	slow := &tailClient{events: make(chan []byte, 2)}
+	s.clients[slow] = struct{}{}
+
+	start := time.Now()
+	for i := 0; i < 10; i++ {
+		s.Send(message.NewDefaultMessage(level.Info, "flood"))
+	}
+	assert.True(time.Since(start) < time.Second)
+
+	assert.True(slow.dropped)
+	assert.Equal(0, s.Clients())
+	assert.EqualValues(1, s.Stats().Dropped)
+
+	// the queued messages are still delivered before the client
+	// sees that it was disconnected.
+	count := 0
+	for range slow.events {
+		count++
+	}
+	assert.Equal(2, count)
+}
@@ -0,0 +1,182 @@
+package send
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mongodb/grip/level"
+	"github.com/mongodb/grip/message"
+)
+
+// TemplateData is the data that formatters that NewTemplateFormatter
+// creates pass to their templates, for each message.
+type TemplateData struct {
+	// Priority is the priority of the message, which renders as
+	// the name of the priority (e.g. "info").
+	Priority level.Priority
+
+	// Name is the logger name, which is the value of the "logger"
+	// field of messages whose Raw form is a message.Fields value,
+	// and is otherwise the name of the formatter.
+	Name string
+
+	// Time is the time of the message, for message.Fields and
+	// stack messages, and is otherwise the time of formatting.
+	Time time.Time
+
+	// Message is the string form of the message.
+	Message string
+
+	// Fields is the Raw form of the message, if it is a
+	// message.Fields value, and is otherwise nil.
+	Fields message.Fields
+
+	// Raw is the Raw form of the message.
+	Raw interface{}
+
+	// Frames is the stack trace of stack messages, and is
+	// otherwise empty.
+	Frames []message.StackFrame
+
+	composer message.Composer
+}
+
+// CallSite returns the location in the program that logged the
+// message: the call site that the Journaler recorded, the first frame
+// of stack messages, or otherwise the first frame on the current stack
+// that is outside of grip. The call site of messages that are sent
+// asynchronously (e.g. by a buffered sender) is only meaningful if it
+// was recorded or the message is a stack message.
+func (d *TemplateData) CallSite() message.StackFrame {
+	if frame, ok := message.GetCallSite(d.composer); ok {
+		return frame
+	}
+
+	return message.CaptureCallSite()
+}
+
+// Composer returns the message.
+func (d *TemplateData) Composer() message.Composer { return d.composer }
+
+// MakeTemplateFormatter returns a MessageFormatter that renders messages
+// with a text/template template; see NewTemplateFormatter.
+func MakeTemplateFormatter(tmpl string) (MessageFormatter, error) {
+	return NewTemplateFormatter("", tmpl)
+}
+
+// NewTemplateFormatter returns a MessageFormatter that renders messages
+// with a text/template template, whose data is a *TemplateData value,
+// for example:
+//
+//    {{.Time | rfc3339}} {{.Priority | upper | pad 9}} [{{.Name}}] {{.Message}}
+//    {{with .CallSite}}{{.File | shortfile}}:{{.Line}}{{end}} {{.Message}}
+//    {{.Message | truncate 80}} {{.Fields | json}}
+//
+// In addition to the standard template functions, templates can use
+// the following functions:
+//
+//    upper, lower: convert a value's string form to upper or lower case.
+//    pad <width>: pads a value's string form with spaces, on the right,
+//        to the width.
+//    truncate <length>: truncates a value's string form to the
+//        length, in characters, ending with "..." when truncated.
+//    json: renders a value as JSON.
+//    timefmt <layout>: formats a time with the layout, as in
+//        time.Time.Format.
+//    rfc3339, unix: format a time in the RFC 3339 format (with
+//        nanoseconds), or as seconds since the Unix epoch.
+//    shortfile: shortens a file path to its file name and the name
+//        of its directory.
+//
+// Returns an error if the template is not valid, including if it
+// refers to fields or methods that TemplateData does not have. The
+// formatter returns an error if the template fails to render.
+func NewTemplateFormatter(name, tmpl string) (MessageFormatter, error) {
+	t, err := template.New("formatter").Funcs(templateFuncs).Parse(tmpl)
+	if err != nil {
+		return nil, fmt.Errorf("problem parsing formatter template: %s", err.Error())
+	}
+
+	// render an example message, to catch errors that parsing
+	// cannot, such as references to missing fields.
+	sample := newTemplateData(name, message.NewFieldsMessage(level.Info, "message", message.Fields{}))
+	sample.Frames = []message.StackFrame{{Function: "main.main", File: "main.go", Line: 1}}
+	if err = t.Execute(&bytes.Buffer{}, sample); err != nil {
+		return nil, fmt.Errorf("formatter template is not valid: %s", err.Error())
+	}
+
+	return func(m message.Composer) (string, error) {
+		buf := &bytes.Buffer{}
+		if err := t.Execute(buf, newTemplateData(name, m)); err != nil {
+			return "", err
+		}
+
+		return buf.String(), nil
+	}, nil
+}
+
+func newTemplateData(name string, m message.Composer) *TemplateData {
+	d := &TemplateData{
+		Priority: m.Priority(),
+		Name:     name,
+		Message:  m.String(),
+		Raw:      m.Raw(),
+		composer: m,
+	}
+
+	switch raw := d.Raw.(type) {
+	case message.Fields:
+		d.Fields = raw
+		if logger, ok := raw["logger"].(string); ok && logger != "" {
+			d.Name = logger
+		}
+		d.Time, _ = raw["time"].(time.Time)
+	case message.StackTrace:
+		d.Frames = raw.Frames
+		d.Time = raw.Time
+	}
+
+	if d.Time.IsZero() {
+		d.Time = time.Now()
+	}
+
+	return d
+}
+
+var templateFuncs = template.FuncMap{
+	"upper": func(v interface{}) string { return strings.ToUpper(fmt.Sprint(v)) },
+	"lower": func(v interface{}) string { return strings.ToLower(fmt.Sprint(v)) },
+	"pad": func(width int, v interface{}) string {
+		str := fmt.Sprint(v)
+		if n := utf8.RuneCountInString(str); n < width {
+			str += strings.Repeat(" ", width-n)
+		}
+		return str
+	},
+	"truncate": func(length int, v interface{}) string {
+		str := fmt.Sprint(v)
+		if utf8.RuneCountInString(str) <= length {
+			return str
+		}
+		if length <= 3 {
+			return string([]rune(str)[:length])
+		}
+		return string([]rune(str)[:length-3]) + "..."
+	},
+	"json": func(v interface{}) (string, error) {
+		out, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		return string(out), nil
+	},
+	"timefmt":   func(layout string, t time.Time) string { return t.Format(layout) },
+	"rfc3339":   func(t time.Time) string { return t.Format(time.RFC3339Nano) },
+	"unix":      func(t time.Time) int64 { return t.Unix() },
+	"shortfile": shortFileName,
+}
@@ -0,0 +1,109 @@
+package send
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mongodb/grip/level"
+	"github.com/mongodb/grip/message"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTemplateFormatter(t *testing.T) {
+	assert := assert.New(t)
+	ts := time.Date(2018, time.March, 4, 5, 6, 7, 0, time.UTC)
+
+	fm, err := NewTemplateFormatter("app", `{{.Time | rfc3339}} {{.Priority | upper | pad 8}}|[{{.Name}}] {{.Message | truncate 10}} {{.Fields.user}}`)
+	assert.NoError(err)
+
+	out, err := fm(message.NewFieldsMessage(level.Warning, "disk", message.Fields{"time": ts, "user": "jane"}))
+	assert.NoError(err)
+	assert.Equal("2018-03-04T05:06:07Z WARNING |[app] [msg='d... jane", out)
+
+	out, err = fm(message.NewFieldsMessage(level.Info, "", message.Fields{"time": ts, "logger": "worker"}))
+	assert.NoError(err)
+	assert.Contains(out, "[worker]")
+
+	fm, err = MakeTemplateFormatter(`{{.Fields | json}} {{timefmt "2006" .Time}} {{.Time | unix}} {{.Priority | lower}}`)
+	assert.NoError(err)
+	out, err = fm(message.NewFields(level.Error, message.Fields{"time": ts, "n": 1}))
+	assert.NoError(err)
+	assert.Equal(`{"msg":"","n":1,"time":"2018-03-04T05:06:07Z"} 2018 1520139967 error`, out)
+
+	// the formatter reports errors from rendering
+	out, err = fm(message.NewFields(level.Error, message.Fields{"bad": make(chan int)}))
+	assert.Error(err)
+	assert.Equal("", out)
+}
+
+func TestTemplateFormatterStackAndCallSite(t *testing.T) {
+	assert := assert.New(t)
+
+	fm, err := MakeTemplateFormatter(`{{with .CallSite}}{{.File | shortfile}}{{end}} {{len .Frames}}`)
+	assert.NoError(err)
+
+	out, err := fm(message.NewDefaultMessage(level.Info, "hello"))
+	assert.NoError(err)
+	assert.Equal("send/template_test.go 0", out)
+
+	m := message.NewStack(1, "stacked")
+	fm, err = MakeTemplateFormatter(`{{(index .Frames 0).Function}} {{.CallSite.Line}} {{.Composer.Loggable}}`)
+	assert.NoError(err)
+	out, err = fm(m)
+	assert.NoError(err)
+	assert.True(strings.HasPrefix(out, "github.com/mongodb/grip/send.TestTemplateFormatterStackAndCallSite "), out)
+	assert.True(strings.HasSuffix(out, " true"), out)
+
+	// the call site skips frames in grip, so senders report the
+	// caller of the logging method.
+	dir, err := ioutil.TempDir("", "grip-template")
+	assert.NoError(err)
+	defer os.RemoveAll(dir)
+
+	sender, err := NewFileLogger("file", filepath.Join(dir, "log"), LevelInfo{level.Info, level.Info})
+	assert.NoError(err)
+	fm, err = MakeTemplateFormatter(`{{.CallSite.Function}}`)
+	assert.NoError(err)
+	assert.NoError(sender.SetFormatter(fm))
+	sender.Send(message.NewDefaultMessage(level.Info, "hello"))
+	assert.NoError(sender.Close())
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "log"))
+	assert.NoError(err)
+	assert.True(strings.HasSuffix(string(data), " github.com/mongodb/grip/send.TestTemplateFormatterStackAndCallSite\n"), string(data))
+}
+
+func TestTemplateFormatterValidation(t *testing.T) {
+	assert := assert.New(t)
+
+	for tmpl, problem := range map[string]string{
+		`{{.Message`:          "problem parsing formatter template",
+		`{{.Missing}}`:        "can't evaluate field Missing",
+		`{{.Message | nope}}`: `function "nope" not defined`,
+		`{{pad "x" .Name}}`:   "formatter template is not valid",
+	} {
+		fm, err := MakeTemplateFormatter(tmpl)
+		assert.Nil(fm)
+		if assert.Error(err, tmpl) {
+			assert.Contains(err.Error(), problem, tmpl)
+		}
+	}
+
+	conf := &FormatterConfig{Type: "template", Options: map[string]interface{}{"template": "{{.Name}}: {{.Message}}", "name": "app"}}
+	factory, ok := getFormatterFactory("template")
+	if assert.True(ok) {
+		fm, err := factory(conf)
+		assert.NoError(err)
+		out, err := fm(message.NewErrorMessage(level.Error, errors.New("failed")))
+		assert.NoError(err)
+		assert.Equal("app: failed", out)
+
+		_, err = factory(&FormatterConfig{Type: "template"})
+		assert.Error(err)
+	}
+}
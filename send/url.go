@@ -0,0 +1,390 @@
+package send
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/mongodb/grip/level"
+)
+
+// URLFactory constructs a Sender from a URL, for use with FromURL. The
+// factory is only called if the URL's query contains no parameters
+// other than the common parameters and the parameters registered for
+// the scheme.
+type URLFactory func(u *url.URL) (Sender, error)
+
+// urlCommonParams are the query parameters that FromURL handles for
+// every scheme.
+var urlCommonParams = []string{"name", "level", "default", "format"}
+
+type urlScheme struct {
+	factory URLFactory
+	params  map[string]struct{}
+}
+
+var urlRegistry = struct {
+	schemes map[string]urlScheme
+	mutex   sync.RWMutex
+}{
+	schemes: map[string]urlScheme{},
+}
+
+// RegisterURLScheme makes a URL scheme available to FromURL. The
+// params are the names of the query parameters that the factory
+// accepts, in addition to the common parameters. Returns an error if
+// the scheme is empty or already registered, or if the factory is nil.
+func RegisterURLScheme(scheme string, factory URLFactory, params ...string) error {
+	if scheme == "" || factory == nil {
+		return errors.New("url schemes must have a name and a factory")
+	}
+
+	urlRegistry.mutex.Lock()
+	defer urlRegistry.mutex.Unlock()
+
+	scheme = strings.ToLower(scheme)
+	if _, ok := urlRegistry.schemes[scheme]; ok {
+		return fmt.Errorf("url scheme '%s' is already registered", scheme)
+	}
+
+	entry := urlScheme{factory: factory, params: map[string]struct{}{}}
+	for _, p := range params {
+		entry.params[p] = struct{}{}
+	}
+	urlRegistry.schemes[scheme] = entry
+
+	return nil
+}
+
+// URLSchemes returns the registered URL schemes, in alphabetical
+// order.
+func URLSchemes() []string {
+	urlRegistry.mutex.RLock()
+	defer urlRegistry.mutex.RUnlock()
+
+	out := make([]string, 0, len(urlRegistry.schemes))
+	for scheme := range urlRegistry.schemes {
+		out = append(out, scheme)
+	}
+	sort.Strings(out)
+
+	return out
+}
+
+// FromURL constructs a Sender from a URL, which makes it possible to
+// configure logging with a single command line flag or environment
+// variable. For example:
+//
+//    file:///var/log/app.log?rotate=100MB&format=json
+//    syslog+tcp://collector:514?facility=local3&level=info
+//    stderr://?level=debug
+//
+// The URL's scheme selects the kind of Sender (see URLSchemes, and
+// RegisterURLScheme to add schemes), and the following query
+// parameters are available for all schemes:
+//
+//    name: the name of the Sender, which defaults to the name that
+//          the scheme gives it.
+//    level: the threshold priority (e.g. "info"), which defaults to info.
+//    default: the default priority, which defaults to notice.
+//    format: a formatter type registered with RegisterFormatterType
+//            (e.g. "json" or "plain".)
+//
+// The built-in schemes are:
+//
+//    stdout://, native://: writes to standard output.
+//    stderr://: writes to standard error.
+//    stream://stdout, stream://stderr: writes messages, without
+//        prefixes, to standard output or standard error.
+//    file:///path: writes to a file. The "rotate" parameter (e.g.
+//        "100MB") rotates the file when it reaches the size, and
+//        "keep" is the number of rotated files to keep (default 5.)
+//    slack://channel: posts to the channel, using the token in the
+//        GRIP_SLACK_CLIENT_TOKEN environment variable. The
+//        "hostname", "fields", and "basic_metadata" parameters
+//        configure the messages.
+//    syslog://, syslog+udp://host:port, syslog+tcp://host:port,
+//    syslog+tls://host:port, syslog+unix:///path: writes to the
+//        local or a remote syslog service, with the "facility"
+//        parameter (e.g. "local3".) The default port is 514, or 6514
+//        for TLS. Not available on Windows.
+//    systemd://: writes to the systemd journal. Only available on
+//        Linux.
+//
+// For the stdout, native, and file schemes, format=json writes one
+// JSON document per line, as with the JSON Senders.
+func FromURL(str string) (Sender, error) {
+	u, err := url.Parse(str)
+	if err != nil {
+		return nil, fmt.Errorf("invalid logging url: %s", err.Error())
+	}
+
+	fail := func(msg string, args ...interface{}) error {
+		return fmt.Errorf("invalid logging url '%s': %s", str, fmt.Sprintf(msg, args...))
+	}
+
+	if u.Scheme == "" {
+		return nil, fail("must specify a scheme (e.g. 'file://')")
+	}
+
+	urlRegistry.mutex.RLock()
+	scheme, ok := urlRegistry.schemes[strings.ToLower(u.Scheme)]
+	urlRegistry.mutex.RUnlock()
+
+	if !ok {
+		return nil, fail("unknown scheme '%s'", u.Scheme)
+	}
+
+	query, err := url.ParseQuery(u.RawQuery)
+	if err != nil {
+		return nil, fail("invalid query: %s", err.Error())
+	}
+
+	params := make([]string, 0, len(query))
+	for param := range query {
+		params = append(params, param)
+	}
+	sort.Strings(params)
+
+	for _, param := range params {
+		if len(query[param]) > 1 {
+			return nil, fail("parameter '%s' is specified more than once", param)
+		}
+
+		if _, ok := scheme.params[param]; !ok && !stringSliceContains(urlCommonParams, param) {
+			return nil, fail("unknown parameter '%s' for scheme '%s'", param, u.Scheme)
+		}
+	}
+
+	l := LevelInfo{Default: level.Notice, Threshold: level.Info}
+	if v := query.Get("level"); v != "" {
+		if l.Threshold = level.FromString(v); l.Threshold == level.Invalid {
+			return nil, fail("level '%s' is not a valid priority", v)
+		}
+	}
+
+	if v := query.Get("default"); v != "" {
+		if l.Default = level.FromString(v); l.Default == level.Invalid {
+			return nil, fail("default '%s' is not a valid priority", v)
+		}
+	}
+
+	var fmtr MessageFormatter
+	if v := query.Get("format"); v != "" {
+		factory, ok := getFormatterFactory(v)
+		if !ok {
+			return nil, fail("unknown format '%s'", v)
+		}
+
+		if fmtr, err = factory(&FormatterConfig{Type: v}); err != nil {
+			return nil, fail("format '%s': %s", v, err.Error())
+		}
+	}
+
+	s, err := scheme.factory(u)
+	if err != nil {
+		return nil, fail("%s", err.Error())
+	}
+
+	// always set the name, because some senders (e.g. syslog)
+	// only connect when their name is set.
+	name := query.Get("name")
+	if name == "" {
+		name = s.Name()
+	}
+	s.SetName(name)
+
+	if err = s.SetLevel(l); err == nil && fmtr != nil {
+		err = s.SetFormatter(fmtr)
+	}
+
+	if err != nil {
+		_ = s.Close()
+		return nil, fail("%s", err.Error())
+	}
+
+	return s, nil
+}
+
+func stringSliceContains(slice []string, item string) bool {
+	for _, s := range slice {
+		if s == item {
+			return true
+		}
+	}
+
+	return false
+}
+
+func init() {
+	for scheme, factory := range map[string]URLFactory{
+		"stdout": buildNativeURLSender,
+		"native": buildNativeURLSender,
+		"stderr": buildErrorURLSender,
+		"stream": buildStreamURLSender,
+	} {
+		if err := RegisterURLScheme(scheme, factory); err != nil {
+			panic(err)
+		}
+	}
+
+	if err := RegisterURLScheme("file", buildFileURLSender, "rotate", "keep"); err != nil {
+		panic(err)
+	}
+
+	if err := RegisterURLScheme("slack", buildSlackURLSender, "hostname", "fields", "basic_metadata"); err != nil {
+		panic(err)
+	}
+}
+
+// urlNoTarget returns an error if the URL has a host or a path, for
+// schemes whose Senders do not have a target.
+func urlNoTarget(u *url.URL) error {
+	if u.Host != "" || u.Path != "" || u.Opaque != "" {
+		return fmt.Errorf("%s urls do not have a host or path", u.Scheme)
+	}
+
+	return nil
+}
+
+func buildNativeURLSender(u *url.URL) (Sender, error) {
+	if err := urlNoTarget(u); err != nil {
+		return nil, err
+	}
+
+	if u.Query().Get("format") == "json" {
+		return MakeJSONConsoleLogger(), nil
+	}
+
+	return MakeNative(), nil
+}
+
+func buildErrorURLSender(u *url.URL) (Sender, error) {
+	if err := urlNoTarget(u); err != nil {
+		return nil, err
+	}
+
+	return MakeErrorLogger(), nil
+}
+
+func buildStreamURLSender(u *url.URL) (Sender, error) {
+	if u.Path != "" || u.Opaque != "" {
+		return nil, errors.New("stream urls do not have a path")
+	}
+
+	switch u.Host {
+	case "stdout":
+		return MakeStreamLogger(os.Stdout), nil
+	case "stderr":
+		return MakeStreamLogger(os.Stderr), nil
+	default:
+		return nil, fmt.Errorf("stream '%s' is not 'stdout' or 'stderr'", u.Host)
+	}
+}
+
+func buildFileURLSender(u *url.URL) (Sender, error) {
+	if u.Host != "" && u.Host != "localhost" {
+		return nil, fmt.Errorf("file urls cannot specify a host ('%s')", u.Host)
+	}
+
+	// relative paths (e.g. "file:app.log") are opaque
+	path := u.Path
+	if u.Opaque != "" {
+		path = u.Opaque
+	}
+
+	if path == "" {
+		return nil, errors.New("must specify a path")
+	}
+
+	query := u.Query()
+
+	var maxSize int64
+	if v := query.Get("rotate"); v != "" {
+		size, err := parseByteSize(v)
+		if err != nil {
+			return nil, fmt.Errorf("rotate: %s", err.Error())
+		}
+		maxSize = size
+	}
+
+	keep := 5
+	if v := query.Get("keep"); v != "" {
+		num, err := strconv.Atoi(v)
+		if err != nil || num < 1 {
+			return nil, fmt.Errorf("keep: '%s' is not a positive number", v)
+		}
+		keep = num
+	}
+
+	var s Sender
+	var err error
+	if query.Get("format") == "json" {
+		s, err = MakeJSONFileLogger(path)
+	} else {
+		s, err = MakeFileLogger(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	f := s.(*nativeLogger).file
+	f.mutex.Lock()
+	f.maxSize = maxSize
+	f.keep = keep
+	f.mutex.Unlock()
+
+	return s, nil
+}
+
+// parseByteSize parses sizes such as "100MB", "512k", or "1048576",
+// where the units are powers of 1024.
+func parseByteSize(str string) (int64, error) {
+	num := strings.ToUpper(strings.TrimSpace(str))
+	num = strings.TrimSuffix(num, "B")
+
+	multiplier := int64(1)
+	for suffix, m := range map[string]int64{"K": 1 << 10, "M": 1 << 20, "G": 1 << 30} {
+		if strings.HasSuffix(num, suffix) {
+			num = strings.TrimSuffix(num, suffix)
+			multiplier = m
+			break
+		}
+	}
+
+	size, err := strconv.ParseInt(strings.TrimSpace(num), 10, 64)
+	if err != nil || size <= 0 {
+		return 0, fmt.Errorf("'%s' is not a valid size", str)
+	}
+
+	return size * multiplier, nil
+}
+
+func buildSlackURLSender(u *url.URL) (Sender, error) {
+	if u.Host == "" || u.Path != "" {
+		return nil, errors.New("slack urls must specify a channel, e.g. 'slack://alerts'")
+	}
+
+	query := u.Query()
+	opts := &SlackOptions{
+		Channel:  u.Host,
+		Hostname: query.Get("hostname"),
+	}
+
+	for param, out := range map[string]*bool{"fields": &opts.Fields, "basic_metadata": &opts.BasicMetadata} {
+		if v := query.Get(param); v != "" {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return nil, fmt.Errorf("%s: '%s' is not a boolean", param, v)
+			}
+			*out = b
+		}
+	}
+
+	return MakeSlackLogger(opts)
+}
@@ -0,0 +1,93 @@
+//go:build linux || freebsd || solaris || darwin
+// +build linux freebsd solaris darwin
+
+package send
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/syslog"
+	"net/url"
+	"strings"
+)
+
+var syslogFacilities = map[string]syslog.Priority{
+	"kern":     syslog.LOG_KERN,
+	"user":     syslog.LOG_USER,
+	"mail":     syslog.LOG_MAIL,
+	"daemon":   syslog.LOG_DAEMON,
+	"auth":     syslog.LOG_AUTH,
+	"syslog":   syslog.LOG_SYSLOG,
+	"lpr":      syslog.LOG_LPR,
+	"news":     syslog.LOG_NEWS,
+	"uucp":     syslog.LOG_UUCP,
+	"cron":     syslog.LOG_CRON,
+	"authpriv": syslog.LOG_AUTHPRIV,
+	"ftp":      syslog.LOG_FTP,
+	"local0":   syslog.LOG_LOCAL0,
+	"local1":   syslog.LOG_LOCAL1,
+	"local2":   syslog.LOG_LOCAL2,
+	"local3":   syslog.LOG_LOCAL3,
+	"local4":   syslog.LOG_LOCAL4,
+	"local5":   syslog.LOG_LOCAL5,
+	"local6":   syslog.LOG_LOCAL6,
+	"local7":   syslog.LOG_LOCAL7,
+}
+
+func init() {
+	for _, scheme := range []string{"syslog", "syslog+udp", "syslog+tcp", "syslog+unix", "syslog+tls"} {
+		if err := RegisterURLScheme(scheme, buildSyslogURLSender, "facility"); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// buildSyslogURLSender connects to the local syslog service for
+// "syslog://" URLs without a host, and otherwise to the syslog service
+// at the URL's address, over UDP unless the scheme specifies the
+// network. "syslog+tls" URLs use RFC 5425 framing, and verify the
+// server's certificate with the system's roots.
+func buildSyslogURLSender(u *url.URL) (Sender, error) {
+	network := "udp"
+	if idx := strings.Index(u.Scheme, "+"); idx >= 0 {
+		network = strings.ToLower(u.Scheme[idx+1:])
+	}
+
+	var raddr string
+	switch {
+	case network == "unix":
+		if u.Host != "" || u.Path == "" {
+			return nil, errors.New("syslog+unix urls must specify a socket path, e.g. 'syslog+unix:///dev/log'")
+		}
+		raddr = u.Path
+	case u.Path != "" && u.Path != "/", u.Opaque != "":
+		return nil, errors.New("syslog urls cannot specify a path")
+	case u.Host == "" && strings.Contains(u.Scheme, "+"):
+		return nil, fmt.Errorf("%s urls must specify a host, e.g. '%s://collector:514'", u.Scheme, u.Scheme)
+	case u.Host == "":
+		// connect to the local syslog service
+		network = ""
+	case u.Port() == "" && network == "tls":
+		raddr = u.Host + ":6514"
+	case u.Port() == "":
+		raddr = u.Host + ":514"
+	default:
+		raddr = u.Host
+	}
+
+	priority := syslog.LOG_DEBUG
+	if v := u.Query().Get("facility"); v != "" {
+		facility, ok := syslogFacilities[strings.ToLower(v)]
+		if !ok {
+			return nil, fmt.Errorf("facility '%s' is not valid", v)
+		}
+		priority |= facility
+	}
+
+	if network == "tls" {
+		return makeSyslogTLSSender(raddr, priority&^syslog.LOG_DEBUG, &tls.Config{ServerName: u.Hostname()}), nil
+	}
+
+	return makeSysLogger(network, raddr, priority), nil
+}
@@ -0,0 +1,37 @@
+//go:build linux || freebsd || solaris || darwin
+// +build linux freebsd solaris darwin
+
+package send
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/mongodb/grip/level"
+	"github.com/mongodb/grip/message"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromURLSyslogWithoutName(t *testing.T) {
+	assert := assert.New(t)
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	s, err := FromURL("syslog+udp://" + conn.LocalAddr().String() + "?facility=local3")
+	require.NoError(t, err)
+	defer s.Close()
+
+	s.Send(message.NewDefaultMessage(level.Error, "over udp"))
+
+	buf := make([]byte, 1024)
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	n, _, err := conn.ReadFrom(buf)
+	require.NoError(t, err)
+	// local3 (19 << 3) | err (3)
+	assert.Contains(string(buf[:n]), "<155>")
+	assert.Contains(string(buf[:n]), "over udp")
+}
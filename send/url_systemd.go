@@ -0,0 +1,20 @@
+//go:build linux
+// +build linux
+
+package send
+
+import "net/url"
+
+func init() {
+	if err := RegisterURLScheme("systemd", buildSystemdURLSender); err != nil {
+		panic(err)
+	}
+}
+
+func buildSystemdURLSender(u *url.URL) (Sender, error) {
+	if err := urlNoTarget(u); err != nil {
+		return nil, err
+	}
+
+	return MakeSystemdLogger(), nil
+}
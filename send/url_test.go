@@ -0,0 +1,164 @@
+package send
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mongodb/grip/level"
+	"github.com/mongodb/grip/message"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromURLErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	for str, expected := range map[string]string{
+		"/var/log/app.log":                    "must specify a scheme",
+		"carrier-pigeon://coop":               "unknown scheme 'carrier-pigeon'",
+		"file:///tmp/app.log?colour=red":      "unknown parameter 'colour' for scheme 'file'",
+		"file:///tmp/app.log?level=a&level=b": "parameter 'level' is specified more than once",
+		"stdout://?level=loud":                "level 'loud' is not a valid priority",
+		"stdout://?default=0":                 "default '0' is not a valid priority",
+		"stdout://?format=fancy":              "unknown format 'fancy'",
+		"stdout://host":                       "stdout urls do not have a host or path",
+		"stream://stdin":                      "stream 'stdin' is not 'stdout' or 'stderr'",
+		"file://remote/tmp/app.log":           "file urls cannot specify a host ('remote')",
+		"file://":                             "must specify a path",
+		"file:///tmp/app.log?rotate=lots":     "rotate: 'lots' is not a valid size",
+		"file:///tmp/app.log?keep=0":          "keep: '0' is not a positive number",
+		"slack://":                            "slack urls must specify a channel",
+		"%zz://":                              "invalid logging url",
+	} {
+		s, err := FromURL(str)
+		assert.Nil(s, str)
+		if assert.Error(err, str) {
+			assert.Contains(err.Error(), expected, str)
+		}
+	}
+}
+
+func TestFromURLCommonParameters(t *testing.T) {
+	assert := assert.New(t)
+
+	s, err := FromURL("stderr://?name=cli&level=debug&default=info&format=plain")
+	assert.NoError(err)
+	assert.Equal("cli", s.Name())
+	assert.Equal(LevelInfo{Default: level.Info, Threshold: level.Debug}, s.Level())
+
+	s, err = FromURL("STDOUT:")
+	assert.NoError(err)
+	assert.Equal(LevelInfo{Default: level.Notice, Threshold: level.Info}, s.Level())
+
+	s, err = FromURL("stream://stderr")
+	assert.NoError(err)
+	assert.NoError(s.Close())
+
+	// senders keep the names their factories give them
+	assert.NoError(RegisterURLScheme("named-test", func(*url.URL) (Sender, error) {
+		sink := MakeInternalLogger()
+		sink.SetName("factory")
+		return sink, nil
+	}))
+	defer func() {
+		urlRegistry.mutex.Lock()
+		delete(urlRegistry.schemes, "named-test")
+		urlRegistry.mutex.Unlock()
+	}()
+
+	s, err = FromURL("named-test://")
+	assert.NoError(err)
+	assert.Equal("factory", s.Name())
+}
+
+func TestFromURLFile(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "grip-url")
+	assert.NoError(err)
+	defer os.RemoveAll(dir)
+	fn := filepath.Join(dir, "app.log")
+
+	s, err := FromURL(fmt.Sprintf("file://%s?format=json&rotate=160b&keep=2", filepath.ToSlash(fn)))
+	assert.NoError(err)
+
+	for i := 0; i < 8; i++ {
+		s.Send(message.NewFields(level.Info, message.Fields{"msg": "rotating", "idx": i}))
+	}
+	assert.NoError(s.Close())
+
+	// each message is about 70 bytes, so each file holds two
+	// messages, and only two rotated files are kept.
+	for _, name := range []string{fn, fn + ".1", fn + ".2"} {
+		data, err := ioutil.ReadFile(name)
+		assert.NoError(err)
+
+		lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+		assert.Len(lines, 2, name)
+		for _, line := range lines {
+			doc := map[string]interface{}{}
+			assert.NoError(json.Unmarshal([]byte(line), &doc))
+			assert.Equal("rotating", doc["msg"])
+		}
+	}
+
+	_, err = os.Stat(fn + ".3")
+	assert.True(os.IsNotExist(err))
+}
+
+func TestRegisterURLScheme(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Error(RegisterURLScheme("", buildNativeURLSender))
+	assert.Error(RegisterURLScheme("example", nil))
+	assert.Error(RegisterURLScheme("FILE", buildFileURLSender))
+
+	sink := MakeInternalLogger()
+	assert.NoError(RegisterURLScheme("internal-test", func(u *url.URL) (Sender, error) {
+		assert.Equal("queue", u.Host)
+		assert.Equal("10", u.Query().Get("size"))
+		return sink, nil
+	}, "size"))
+	defer func() {
+		urlRegistry.mutex.Lock()
+		delete(urlRegistry.schemes, "internal-test")
+		urlRegistry.mutex.Unlock()
+	}()
+	assert.Contains(URLSchemes(), "internal-test")
+
+	s, err := FromURL("internal-test://queue?size=10&name=queue&level=warning")
+	assert.NoError(err)
+	assert.Equal(sink, s)
+	assert.Equal("queue", s.Name())
+	assert.Equal(level.Warning, s.Level().Threshold)
+
+	_, err = FromURL("internal-test://queue?rotate=10MB")
+	assert.Error(err)
+}
+
+func TestParseByteSize(t *testing.T) {
+	assert := assert.New(t)
+
+	for str, expected := range map[string]int64{
+		"512":   512,
+		"100b":  100,
+		"1k":    1024,
+		"10KB":  10 << 10,
+		"100MB": 100 << 20,
+		"2g":    2 << 30,
+	} {
+		size, err := parseByteSize(str)
+		assert.NoError(err, str)
+		assert.Equal(expected, size, str)
+	}
+
+	for _, str := range []string{"", "MB", "-1MB", "1TB", "1.5MB"} {
+		_, err := parseByteSize(str)
+		assert.Error(err, str)
+	}
+}
@@ -1,10 +1,12 @@
 package send
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
 	"strings"
+	"time"
 
 	xmpp "github.com/mattn/go-xmpp"
 	"github.com/mongodb/grip/message"
@@ -131,23 +133,40 @@ func constructXMPPLogger(name, target string, info XMPPConnectionInfo) (Sender,
 }
 
 func (s *xmppLogger) Send(m message.Composer) {
-	if s.level.ShouldLog(m) {
-		text, err := s.formatter(m)
-		if err != nil {
-			s.errHandler(err, m)
-			return
-		}
+	if err := s.SendContext(context.Background(), m); err != nil {
+		s.errHandler(err, m)
+	}
+}
 
-		c := xmpp.Chat{
-			Remote: s.target,
-			Type:   "chat",
-			Text:   text,
-		}
+// SendContext sends the message to the XMPP server. If the context is
+// done first, SendContext returns the context's error, although the
+// message may still be delivered.
+func (s *xmppLogger) SendContext(ctx context.Context, m message.Composer) error {
+	if !s.shouldLog(m) {
+		return nil
+	}
+	start := time.Now()
 
-		if _, err := s.info.client.Send(c); err != nil {
-			s.errHandler(err, m)
-		}
+	text, err := s.formatter(m)
+	if err != nil {
+		return err
+	}
+
+	c := xmpp.Chat{
+		Remote: s.target,
+		Type:   "chat",
+		Text:   text,
 	}
+
+	if err = runContext(ctx, func() error {
+		_, err := s.info.client.Send(c)
+		return err
+	}); err != nil {
+		return err
+	}
+
+	s.recordSend(m, start)
+	return nil
 }
 
 ////////////////////////////////////////////////////////////////////////
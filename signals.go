@@ -0,0 +1,159 @@
+package grip
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/mongodb/grip/level"
+	"github.com/mongodb/grip/send"
+)
+
+// SignalOptions configures the signal handling that HandleSignals
+// installs.
+type SignalOptions struct {
+	// Journaler is the logger that the signal handler controls,
+	// and defaults to the standard logger.
+	Journaler Journaler
+
+	// FlushTimeout is the longest that the handler waits to
+	// flush pending messages when shutting down, and defaults to
+	// 10 seconds.
+	FlushTimeout time.Duration
+
+	// Exit is called, once the handler has flushed and closed the
+	// sender, with an exit code of 128 plus the signal number, as
+	// shells report for processes killed by signals. Defaults to
+	// os.Exit.
+	Exit func(int)
+}
+
+// HandleSignals installs signal handlers that manage the Journaler's
+// sender:
+//
+//   - SIGTERM and SIGINT flush and close the sender, and then exit
+//     the process.
+//   - SIGHUP reopens the files of file senders, so that external
+//     tools can rotate the log files (see send.Reopen.)
+//   - SIGUSR1 raises the logging threshold to the next level, and
+//     SIGUSR2 lowers it, so that you can change the verbosity of a
+//     running process.
+//
+// On Windows, only the shutdown signals are handled. Signal handling
+// is opt-in: grip does not handle any signals unless you call
+// HandleSignals. The returned function stops handling signals, and
+// restores the default behavior for these signals.
+func HandleSignals(opts SignalOptions) func() {
+	if opts.Journaler == nil {
+		opts.Journaler = std
+	}
+
+	if opts.FlushTimeout <= 0 {
+		opts.FlushTimeout = 10 * time.Second
+	}
+
+	if opts.Exit == nil {
+		opts.Exit = os.Exit
+	}
+
+	signals := make(chan os.Signal, 1)
+	done := make(chan struct{})
+
+	all := append([]os.Signal{}, shutdownSignals...)
+	all = append(all, reopenSignals...)
+	all = append(all, raiseSignals...)
+	all = append(all, lowerSignals...)
+	signal.Notify(signals, all...)
+
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case sig := <-signals:
+				if handleSignal(opts, sig) {
+					signal.Stop(signals)
+					return
+				}
+			}
+		}
+	}()
+
+	once := &sync.Once{}
+	return func() {
+		once.Do(func() {
+			signal.Stop(signals)
+			close(done)
+		})
+	}
+}
+
+// handleSignal responds to a signal, and returns true once the
+// handler has shut down.
+func handleSignal(opts SignalOptions, sig os.Signal) bool {
+	j := opts.Journaler
+
+	switch {
+	case containsSignal(shutdownSignals, sig):
+		j.Noticef("received %s, shutting down", sig)
+		j.CatchError(j.Flush(opts.FlushTimeout))
+
+		// there's nowhere left to report errors once the
+		// sender is closed.
+		_ = j.GetSender().Close()
+
+		code := 1
+		if s, ok := sig.(syscall.Signal); ok {
+			code = 128 + int(s)
+		}
+		opts.Exit(code)
+
+		return true
+	case containsSignal(reopenSignals, sig):
+		if err := send.Reopen(j.GetSender()); err != nil {
+			j.Errorf("received %s, could not reopen log files: %s", sig, err)
+			break
+		}
+		j.Noticef("received %s, reopened log files", sig)
+	case containsSignal(raiseSignals, sig):
+		j.SetThreshold(raisePriority(j.ThresholdLevel()))
+		j.Noticef("received %s, set logging threshold to %s", sig, j.ThresholdLevel())
+	case containsSignal(lowerSignals, sig):
+		j.SetThreshold(lowerPriority(j.ThresholdLevel()))
+		j.Noticef("received %s, set logging threshold to %s", sig, j.ThresholdLevel())
+	}
+
+	return false
+}
+
+func containsSignal(signals []os.Signal, sig os.Signal) bool {
+	for _, s := range signals {
+		if s == sig {
+			return true
+		}
+	}
+
+	return false
+}
+
+// raisePriority returns the next named level above the priority, and
+// lowerPriority the next named level below it.
+func raisePriority(p level.Priority) level.Priority {
+	next := (p/10 + 1) * 10
+	if next > level.Emergency {
+		return level.Emergency
+	}
+
+	return next
+}
+
+func lowerPriority(p level.Priority) level.Priority {
+	next := ((p+9)/10 - 1) * 10
+	if next < level.Trace {
+		return level.Trace
+	}
+
+	return next
+}
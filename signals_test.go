@@ -0,0 +1,133 @@
+// +build !windows
+
+package grip
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/mongodb/grip/level"
+	"github.com/mongodb/grip/send"
+	"github.com/stretchr/testify/assert"
+)
+
+// nextLogged returns the next loggable message from the sender.
+func nextLogged(sink *send.InternalSender) string {
+	for {
+		m := sink.GetMessage()
+		if m.Logged {
+			return m.Rendered
+		}
+	}
+}
+
+func TestPriorityStepping(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(level.Notice, raisePriority(level.Info))
+	assert.Equal(level.Notice, raisePriority(level.Priority(45)))
+	assert.Equal(level.Emergency, raisePriority(level.Emergency))
+	assert.Equal(level.Debug, lowerPriority(level.Info))
+	assert.Equal(level.Info, lowerPriority(level.Priority(45)))
+	assert.Equal(level.Trace, lowerPriority(level.Trace))
+}
+
+func TestSignalsChangeThreshold(t *testing.T) {
+	assert := assert.New(t)
+
+	j := NewJournaler("signals")
+	sink, err := send.NewInternalLogger("sink", send.LevelInfo{Default: level.Notice, Threshold: level.Info})
+	assert.NoError(err)
+	assert.NoError(j.SetSender(sink))
+	j.SetThreshold(level.Info)
+
+	stop := HandleSignals(SignalOptions{Journaler: j})
+	defer stop()
+
+	assert.NoError(syscall.Kill(os.Getpid(), syscall.SIGUSR2))
+	assert.Contains(nextLogged(sink), "debug")
+	assert.Equal(level.Debug, j.ThresholdLevel())
+
+	assert.NoError(syscall.Kill(os.Getpid(), syscall.SIGUSR1))
+	assert.Contains(nextLogged(sink), "info")
+	assert.Equal(level.Info, j.ThresholdLevel())
+}
+
+func TestSignalsReopenFiles(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "grip-signals")
+	assert.NoError(err)
+	defer os.RemoveAll(dir)
+	fn := filepath.Join(dir, "app.log")
+
+	file, err := send.MakeFileLogger(fn)
+	assert.NoError(err)
+	sink := send.MakeInternalLogger()
+
+	j := NewJournaler("signals")
+	assert.NoError(j.SetSender(send.NewConfiguredMultiSender(file, sink)))
+	j.SetThreshold(level.Info)
+
+	stop := HandleSignals(SignalOptions{Journaler: j})
+	defer stop()
+
+	j.Info("before rotation")
+	assert.NoError(os.Rename(fn, fn+".1"))
+
+	assert.NoError(syscall.Kill(os.Getpid(), syscall.SIGHUP))
+	assert.Equal("before rotation", nextLogged(sink))
+	assert.Contains(nextLogged(sink), "reopened")
+
+	j.Info("after rotation")
+
+	rotated, err := ioutil.ReadFile(fn + ".1")
+	assert.NoError(err)
+	assert.Contains(string(rotated), "before rotation")
+	assert.NotContains(string(rotated), "after rotation")
+
+	current, err := ioutil.ReadFile(fn)
+	assert.NoError(err)
+	assert.Contains(string(current), "after rotation")
+}
+
+func TestSignalsShutDown(t *testing.T) {
+	assert := assert.New(t)
+
+	sink, err := send.NewInternalLogger("sink", send.LevelInfo{Default: level.Notice, Threshold: level.Info})
+	assert.NoError(err)
+
+	j := NewJournaler("signals")
+	assert.NoError(j.SetSender(send.NewBufferedSender(sink, time.Hour, 100)))
+	j.SetThreshold(level.Info)
+
+	codes := make(chan int, 1)
+	stop := HandleSignals(SignalOptions{Journaler: j, Exit: func(code int) { codes <- code }})
+	defer stop()
+
+	j.Info("buffered")
+	assert.NoError(syscall.Kill(os.Getpid(), syscall.SIGTERM))
+
+	select {
+	case code := <-codes:
+		assert.Equal(128+int(syscall.SIGTERM), code)
+	case <-time.After(5 * time.Second):
+		assert.Fail("signal handler did not exit")
+	}
+
+	// the buffered messages and the shutdown notice were flushed
+	// before the sender was closed.
+	msgs := []string{}
+	for sink.HasMessage() {
+		if m := sink.GetMessage(); m.Logged {
+			msgs = append(msgs, m.Rendered)
+		}
+	}
+	assert.Len(msgs, 1)
+	assert.Contains(msgs[0], "buffered")
+	assert.Contains(msgs[0], "shutting down")
+}
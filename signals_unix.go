@@ -0,0 +1,16 @@
+//go:build !windows
+// +build !windows
+
+package grip
+
+import (
+	"os"
+	"syscall"
+)
+
+var (
+	shutdownSignals = []os.Signal{syscall.SIGTERM, syscall.SIGINT}
+	reopenSignals   = []os.Signal{syscall.SIGHUP}
+	raiseSignals    = []os.Signal{syscall.SIGUSR1}
+	lowerSignals    = []os.Signal{syscall.SIGUSR2}
+)
@@ -0,0 +1,16 @@
+//go:build windows
+// +build windows
+
+package grip
+
+import (
+	"os"
+	"syscall"
+)
+
+var (
+	shutdownSignals = []os.Signal{syscall.SIGTERM, os.Interrupt}
+	reopenSignals   []os.Signal
+	raiseSignals    []os.Signal
+	lowerSignals    []os.Signal
+)
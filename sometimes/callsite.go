@@ -0,0 +1,131 @@
+package sometimes
+
+import (
+	"runtime"
+	"sync"
+	"time"
+)
+
+// Counter implements "first N, then every Mth" sampling: the first
+// calls to Check return true, and afterwards only every Mth call
+// returns true. Counter is safe for concurrent use.
+type Counter struct {
+	first int64
+	every int64
+	count int64
+	mutex sync.Mutex
+}
+
+// NewCounter constructs a Counter that allows the first messages,
+// and then one of every messages. If every is less than or equal to
+// 0, no messages are allowed after the first.
+func NewCounter(first, every int) *Counter {
+	return &Counter{first: int64(first), every: int64(every)}
+}
+
+// Check records a call and returns true if it should be sampled.
+func (c *Counter) Check() bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.count++
+
+	if c.count <= c.first {
+		return true
+	}
+
+	if c.every <= 0 {
+		return false
+	}
+
+	return (c.count-c.first)%c.every == 0
+}
+
+// Limiter caps the number of calls to Check that return true in each
+// one-second interval. Limiter is safe for concurrent use.
+type Limiter struct {
+	limit  int
+	count  int
+	window time.Time
+	mutex  sync.Mutex
+}
+
+// NewLimiter constructs a Limiter that allows no more than perSecond
+// calls to Check to return true every second.
+func NewLimiter(perSecond int) *Limiter {
+	return &Limiter{limit: perSecond}
+}
+
+// Check records a call and returns true if the limit for the current
+// second has not been reached.
+func (l *Limiter) Check() bool {
+	now := time.Now()
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if now.Sub(l.window) >= time.Second {
+		l.window = now
+		l.count = 0
+	}
+
+	if l.count >= l.limit {
+		return false
+	}
+
+	l.count++
+	return true
+}
+
+// the call site registries map the program counter of the caller of
+// FirstThenEvery and PerSecond to the sampler for that call site.
+var (
+	callSiteMutex    sync.Mutex
+	callSiteCounters = map[uintptr]*Counter{}
+	callSiteLimiters = map[uintptr]*Limiter{}
+)
+
+func callSite() uintptr {
+	// skip callSite and its caller within this package
+	pc, _, _, _ := runtime.Caller(2)
+	return pc
+}
+
+// FirstThenEvery returns true for the first calls from a given call
+// site, and then for every Mth call from that call site. For example:
+//
+//	grip.InfoWhen(sometimes.FirstThenEvery(10, 100), msg)
+//
+// logs the first 10 messages, and then every 100th. The arguments
+// passed on the first call from a call site determine the behavior of
+// that call site.
+func FirstThenEvery(first, every int) bool {
+	pc := callSite()
+
+	callSiteMutex.Lock()
+	c, ok := callSiteCounters[pc]
+	if !ok {
+		c = NewCounter(first, every)
+		callSiteCounters[pc] = c
+	}
+	callSiteMutex.Unlock()
+
+	return c.Check()
+}
+
+// PerSecond returns true for no more than n calls from a given call
+// site every second. The argument passed on the first call from a
+// call site determines the limit for that call site.
+func PerSecond(n int) bool {
+	pc := callSite()
+
+	callSiteMutex.Lock()
+	l, ok := callSiteLimiters[pc]
+	if !ok {
+		l = NewLimiter(n)
+		callSiteLimiters[pc] = l
+	}
+	callSiteMutex.Unlock()
+
+	return l.Check()
+}
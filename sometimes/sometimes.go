@@ -1,16 +1,27 @@
+/*
+Package sometimes provides predicates for sampling log messages, for
+use with the conditional (e.g. LogWhen) logging methods.
+
+The simple predicates (Half, Third, Percent, etc.) draw from a
+package-level random source, which you can reseed with Seed for
+reproducible output. Use NewSource to construct independent sources,
+Key for deterministic sampling by an identifier, and FirstThenEvery
+and PerSecond to sample per call site.
+*/
 package sometimes
 
-import (
-	"math/rand"
-	"time"
-)
+import "time"
+
+var std = NewSource(time.Now().UnixNano())
 
-func init() {
-	rand.Seed(time.Now().Unix())
+// Seed resets the package-level random source used by Percent, Rate,
+// and the other simple predicates, with the given seed.
+func Seed(seed int64) {
+	std.Seed(seed)
 }
 
 func getRandNumber() int {
-	return rand.Intn(101)
+	return std.intn(101)
 }
 
 // Fifth returns true 20% of the time.
@@ -57,3 +68,11 @@ func Percent(p int) bool {
 
 	return getRandNumber() > (100 - p)
 }
+
+// Rate takes a fraction between 0 and 1 and returns true that
+// fraction of the time, using the package-level random source. Rates
+// greater than or equal to 1 always return true, and rates less than
+// or equal to 0 always return false.
+func Rate(r float64) bool {
+	return std.Rate(r)
+}
@@ -0,0 +1,103 @@
+package sometimes
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSeededSourcesAreReproducible(t *testing.T) {
+	assert := assert.New(t)
+
+	one := NewSource(42)
+	two := NewSource(42)
+	for i := 0; i < 100; i++ {
+		assert.Equal(one.Rate(0.3), two.Rate(0.3))
+	}
+
+	one.Seed(7)
+	two.Seed(7)
+	for i := 0; i < 100; i++ {
+		assert.Equal(one.Percent(50), two.Percent(50))
+	}
+
+	assert.True(one.Rate(1))
+	assert.False(one.Rate(0))
+	assert.True(Rate(2))
+	assert.False(Rate(-1))
+}
+
+func TestKeyIsDeterministic(t *testing.T) {
+	assert := assert.New(t)
+
+	kept := 0
+	for i := 0; i < 1000; i++ {
+		key := fmt.Sprintf("request-%d", i)
+		first := Key(key, 0.25)
+		assert.Equal(first, Key(key, 0.25))
+		if first {
+			kept++
+		}
+	}
+
+	assert.True(kept > 150 && kept < 350, "kept %d", kept)
+	assert.True(Key("anything", 1))
+	assert.False(Key("anything", 0))
+}
+
+func TestCounter(t *testing.T) {
+	assert := assert.New(t)
+
+	c := NewCounter(2, 3)
+	results := []bool{}
+	for i := 0; i < 8; i++ {
+		results = append(results, c.Check())
+	}
+	assert.Equal([]bool{true, true, false, false, true, false, false, true}, results)
+
+	c = NewCounter(1, 0)
+	assert.True(c.Check())
+	assert.False(c.Check())
+}
+
+func TestLimiter(t *testing.T) {
+	assert := assert.New(t)
+
+	l := NewLimiter(3)
+	count := 0
+	for i := 0; i < 10; i++ {
+		if l.Check() {
+			count++
+		}
+	}
+	assert.Equal(3, count)
+
+	l.window = time.Now().Add(-2 * time.Second)
+	assert.True(l.Check())
+}
+
+func TestCallSitesAreIndependent(t *testing.T) {
+	assert := assert.New(t)
+
+	one, two := 0, 0
+	for i := 0; i < 10; i++ {
+		if FirstThenEvery(1, 5) {
+			one++
+		}
+		if FirstThenEvery(1, 5) {
+			two++
+		}
+	}
+	assert.Equal(2, one)
+	assert.Equal(2, two)
+
+	count := 0
+	for i := 0; i < 10; i++ {
+		if PerSecond(4) {
+			count++
+		}
+	}
+	assert.Equal(4, count)
+}
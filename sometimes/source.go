@@ -0,0 +1,94 @@
+package sometimes
+
+import (
+	"hash/fnv"
+	"math/rand"
+	"sync"
+)
+
+// Source is a seedable random source for sampling decisions. Unlike
+// the package-level predicates, which share a single source, each
+// Source is independent, so that tests and other callers can produce
+// reproducible sequences. Source is safe for concurrent use.
+type Source struct {
+	rand  *rand.Rand
+	mutex sync.Mutex
+}
+
+// NewSource constructs a Source seeded with the specified value.
+func NewSource(seed int64) *Source {
+	return &Source{rand: rand.New(rand.NewSource(seed))}
+}
+
+// Seed resets the Source with the given seed.
+func (s *Source) Seed(seed int64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.rand.Seed(seed)
+}
+
+// Rate returns true the specified fraction (between 0 and 1) of the
+// time.
+func (s *Source) Rate(r float64) bool {
+	if r >= 1 {
+		return true
+	}
+
+	if r <= 0 {
+		return false
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.rand.Float64() < r
+}
+
+// Percent returns true p percent of the time, with the same
+// semantics as the package-level Percent function.
+func (s *Source) Percent(p int) bool {
+	return s.Rate(float64(p) / 100)
+}
+
+func (s *Source) intn(n int) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.rand.Intn(n)
+}
+
+// Key returns true for the specified fraction (between 0 and 1) of
+// all possible keys. The decision is a pure function of the key and
+// the rate, so the same key always produces the same result, in every
+// process. Use this to keep or drop all of the messages related to a
+// single request or operation together.
+func Key(key string, r float64) bool {
+	if r >= 1 {
+		return true
+	}
+
+	if r <= 0 {
+		return false
+	}
+
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key))
+
+	// FNV mixes poorly into the high bits for keys that differ
+	// only in their last bytes (e.g. sequential IDs), so apply a
+	// finalizer before using the top 53 bits of the hash to
+	// produce a uniformly distributed value in [0, 1).
+	return float64(mix(h.Sum64())>>11)/(1<<53) < r
+}
+
+// mix is the splitmix64 finalizer.
+func mix(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+
+	return x
+}